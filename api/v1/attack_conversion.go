@@ -0,0 +1,314 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	v2 "vegeta-controller/api/v2"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// v2SpecAnnotation keeps the spec of v2 which can not be represented in v1, e.g. per minute rates,
+// so that it is restored when the attack is converted back to v2 without changes in v1.
+const v2SpecAnnotation = "vegeta.kaidotdev.github.io/v2-spec"
+
+var _ conversion.Convertible = &Attack{}
+
+// ConvertTo converts this Attack to the Hub version (v2)
+func (src *Attack) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v2.Attack)
+	src = src.DeepCopy()
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = restoreSpecToV2(&dst.ObjectMeta, &src.Spec)
+	return convertStatus(&src.Status, &dst.Status)
+}
+
+// ConvertFrom converts from the Hub version (v2) to this version
+func (dst *Attack) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v2.Attack).DeepCopy()
+
+	dst.ObjectMeta = src.ObjectMeta
+	spec, err := keepSpecFromV2(&dst.ObjectMeta, &src.Spec)
+	if err != nil {
+		return err
+	}
+	dst.Spec = spec
+	return convertStatus(&src.Status, &dst.Status)
+}
+
+var _ conversion.Convertible = &CronAttack{}
+
+// ConvertTo converts this CronAttack to the Hub version (v2)
+func (src *CronAttack) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v2.CronAttack)
+	src = src.DeepCopy()
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v2.CronAttackSpec{
+		Schedule:                      src.Spec.Schedule,
+		StartingDeadlineSeconds:       src.Spec.StartingDeadlineSeconds,
+		ConcurrencyPolicy:             v2.ConcurrencyPolicy(src.Spec.ConcurrencyPolicy),
+		Suspend:                       src.Spec.Suspend,
+		SuccessfulAttacksHistoryLimit: src.Spec.SuccessfulAttacksHistoryLimit,
+		FailedAttacksHistoryLimit:     src.Spec.FailedAttacksHistoryLimit,
+		AttackTemplate: v2.AttackTemplateSpec{
+			ObjectMeta: src.Spec.AttackTemplate.ObjectMeta,
+			// The spec of the template is kept in the annotation of CronAttack, since the template has no place for it
+			Spec: restoreSpecToV2(&dst.ObjectMeta, &src.Spec.AttackTemplate.Spec),
+		},
+	}
+	return convertStatus(&src.Status, &dst.Status)
+}
+
+// ConvertFrom converts from the Hub version (v2) to this version
+func (dst *CronAttack) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v2.CronAttack).DeepCopy()
+
+	dst.ObjectMeta = src.ObjectMeta
+	spec, err := keepSpecFromV2(&dst.ObjectMeta, &src.Spec.AttackTemplate.Spec)
+	if err != nil {
+		return err
+	}
+	dst.Spec = CronAttackSpec{
+		Schedule:                      src.Spec.Schedule,
+		StartingDeadlineSeconds:       src.Spec.StartingDeadlineSeconds,
+		ConcurrencyPolicy:             ConcurrencyPolicy(src.Spec.ConcurrencyPolicy),
+		Suspend:                       src.Spec.Suspend,
+		SuccessfulAttacksHistoryLimit: src.Spec.SuccessfulAttacksHistoryLimit,
+		FailedAttacksHistoryLimit:     src.Spec.FailedAttacksHistoryLimit,
+		AttackTemplate: AttackTemplateSpec{
+			ObjectMeta: src.Spec.AttackTemplate.ObjectMeta,
+			Spec:       spec,
+		},
+	}
+	return convertStatus(&src.Status, &dst.Status)
+}
+
+// restoreSpecToV2 converts the spec to v2, and restores it from v2SpecAnnotation of the object unless it was changed in v1.
+// The annotation is removed from the object of v2.
+func restoreSpecToV2(meta *metaV1.ObjectMeta, spec *AttackSpec) v2.AttackSpec {
+	converted := convertSpecToV2(spec)
+	raw, ok := meta.Annotations[v2SpecAnnotation]
+	if !ok {
+		return converted
+	}
+	delete(meta.Annotations, v2SpecAnnotation)
+	var kept v2.AttackSpec
+	if err := json.Unmarshal([]byte(raw), &kept); err == nil && equality.Semantic.DeepEqual(convertSpecFromV2(&kept), *spec) {
+		return kept
+	}
+	return converted
+}
+
+// keepSpecFromV2 converts the spec from v2, and keeps it in v2SpecAnnotation of the object when it can not be represented in v1
+func keepSpecFromV2(meta *metaV1.ObjectMeta, spec *v2.AttackSpec) (AttackSpec, error) {
+	converted := convertSpecFromV2(spec)
+	if equality.Semantic.DeepEqual(convertSpecToV2(&converted), *spec) {
+		return converted, nil
+	}
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return AttackSpec{}, err
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[v2SpecAnnotation] = string(raw)
+	return converted, nil
+}
+
+func convertSpecToV2(spec *AttackSpec) v2.AttackSpec {
+	converted := v2.AttackSpec{
+		Parallelism: spec.Parallelism,
+		Scenario:    spec.Scenario,
+		Output:      spec.Output,
+		Option: v2.VegetaOption{
+			Duration:    secondsToDuration(spec.Option.Duration),
+			Connections: intToInt32Ptr(spec.Option.Connections),
+			Rate:        intToRate(spec.Option.Rate),
+			RateMode:    v2.RateMode(spec.Option.RateMode),
+			Timeout:     secondsToDuration(spec.Option.Timeout),
+			Workers:     intToInt32Ptr(spec.Option.Workers),
+			Format:      spec.Option.Format,
+		},
+		Template: v2.Template{
+			ObjectMeta: spec.Template.ObjectMeta,
+			Spec: v2.Spec{
+				HostAliases: spec.Template.Spec.HostAliases,
+			},
+		},
+		AttackContainerSpec: v2.AttackContainerSpec{
+			Resources: spec.AttackContainerSpec.Resources,
+		},
+		ReplacePolicy: v2.ReplacePolicy(spec.ReplacePolicy),
+	}
+	// Keepalive is enabled by default, and false is only representable by the pointer in v2
+	if !spec.Option.Keepalive {
+		keepalive := false
+		converted.Option.Keepalive = &keepalive
+	}
+	if spec.ScenarioFrom != nil {
+		converted.ScenarioFrom = &v2.ScenarioSource{
+			ConfigMapKeyRef: spec.ScenarioFrom.ConfigMapKeyRef,
+			SecretKeyRef:    spec.ScenarioFrom.SecretKeyRef,
+		}
+	}
+	if spec.Thresholds != nil {
+		converted.Thresholds = &v2.Thresholds{
+			MaxLatencyP99:      spec.Thresholds.MaxLatencyP99,
+			MaxLatencyP95:      spec.Thresholds.MaxLatencyP95,
+			MaxLatencyMean:     spec.Thresholds.MaxLatencyMean,
+			MinSuccessRatio:    spec.Thresholds.MinSuccessRatio,
+			MinThroughput:      spec.Thresholds.MinThroughput,
+			AllowedStatusCodes: spec.Thresholds.AllowedStatusCodes,
+			MaxErrors:          spec.Thresholds.MaxErrors,
+		}
+	}
+	for _, stage := range spec.Stages {
+		convertedStage := v2.Stage{
+			Rate: intToRate(stage.Rate),
+		}
+		if duration := secondsToDuration(stage.Duration); duration != nil {
+			convertedStage.Duration = *duration
+		}
+		if stage.TargetRate != nil {
+			convertedStage.TargetRate = intToRate(*stage.TargetRate)
+		}
+		converted.Stages = append(converted.Stages, convertedStage)
+	}
+	return converted
+}
+
+func convertSpecFromV2(spec *v2.AttackSpec) AttackSpec {
+	converted := AttackSpec{
+		Parallelism: spec.Parallelism,
+		Scenario:    spec.Scenario,
+		Output:      spec.Output,
+		Option: VegetaOption{
+			Duration:    durationToSeconds(spec.Option.Duration),
+			Connections: int32PtrToInt(spec.Option.Connections),
+			Keepalive:   spec.Option.Keepalive == nil || *spec.Option.Keepalive,
+			Rate:        rateToInt(spec.Option.Rate),
+			RateMode:    RateMode(spec.Option.RateMode),
+			Timeout:     durationToSeconds(spec.Option.Timeout),
+			Workers:     int32PtrToInt(spec.Option.Workers),
+			Format:      spec.Option.Format,
+		},
+		Template: Template{
+			ObjectMeta: spec.Template.ObjectMeta,
+			Spec: Spec{
+				HostAliases: spec.Template.Spec.HostAliases,
+			},
+		},
+		AttackContainerSpec: AttackContainerSpec{
+			Resources: spec.AttackContainerSpec.Resources,
+		},
+		ReplacePolicy: ReplacePolicy(spec.ReplacePolicy),
+	}
+	if spec.ScenarioFrom != nil {
+		converted.ScenarioFrom = &ScenarioSource{
+			ConfigMapKeyRef: spec.ScenarioFrom.ConfigMapKeyRef,
+			SecretKeyRef:    spec.ScenarioFrom.SecretKeyRef,
+		}
+	}
+	if spec.Thresholds != nil {
+		converted.Thresholds = &Thresholds{
+			MaxLatencyP99:      spec.Thresholds.MaxLatencyP99,
+			MaxLatencyP95:      spec.Thresholds.MaxLatencyP95,
+			MaxLatencyMean:     spec.Thresholds.MaxLatencyMean,
+			MinSuccessRatio:    spec.Thresholds.MinSuccessRatio,
+			MinThroughput:      spec.Thresholds.MinThroughput,
+			AllowedStatusCodes: spec.Thresholds.AllowedStatusCodes,
+			MaxErrors:          spec.Thresholds.MaxErrors,
+		}
+	}
+	for _, stage := range spec.Stages {
+		convertedStage := Stage{
+			Duration: durationToSeconds(&stage.Duration),
+			Rate:     rateToInt(stage.Rate),
+		}
+		if stage.TargetRate != nil {
+			targetRate := rateToInt(stage.TargetRate)
+			convertedStage.TargetRate = &targetRate
+		}
+		converted.Stages = append(converted.Stages, convertedStage)
+	}
+	return converted
+}
+
+// convertStatus converts the status through JSON, since it has the same schema in both versions
+// except for the fields added in v2, which are dropped in v1
+func convertStatus(src interface{}, dst interface{}) error {
+	raw, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dst)
+}
+
+func secondsToDuration(seconds string) *metaV1.Duration {
+	if seconds == "" {
+		return nil
+	}
+	// Validated as integer seconds by the schema
+	duration, err := time.ParseDuration(seconds)
+	if err != nil {
+		return nil
+	}
+	return &metaV1.Duration{Duration: duration}
+}
+
+// durationToSeconds truncates the duration to seconds, and the lost precision is kept in v2SpecAnnotation
+func durationToSeconds(duration *metaV1.Duration) string {
+	if duration == nil {
+		return ""
+	}
+	return fmt.Sprintf("%ds", int64(duration.Seconds()))
+}
+
+func intToInt32Ptr(n int) *int32 {
+	if n == 0 {
+		return nil
+	}
+	converted := int32(n)
+	return &converted
+}
+
+func int32PtrToInt(n *int32) int {
+	if n == nil {
+		return 0
+	}
+	return int(*n)
+}
+
+func intToRate(rate int) *intstr.IntOrString {
+	if rate == 0 {
+		return nil
+	}
+	converted := intstr.FromInt(rate)
+	return &converted
+}
+
+// rateToInt approximates the rate by requests per second, and the exact rate is kept in v2SpecAnnotation
+func rateToInt(rate *intstr.IntOrString) int {
+	if rate == nil {
+		return 0
+	}
+	freq, per, err := v2.ParseRate(*rate)
+	if err != nil || freq == 0 {
+		return 0
+	}
+	perSecond := int(math.Round(float64(freq) / per.Seconds()))
+	// Minimum rate of v1 is 1
+	if perSecond < 1 {
+		return 1
+	}
+	return perSecond
+}
@@ -0,0 +1,193 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	v2 "vegeta-controller/api/v2"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func rateOf(value intstr.IntOrString) *intstr.IntOrString {
+	return &value
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestAttackConversionRoundTrip(t *testing.T) {
+	workers := int32(4)
+
+	tests := []struct {
+		name string
+		spec v2.AttackSpec
+		// want is the spec of v1 converted from v2
+		want AttackSpec
+		// wantKept is whether the spec of v2 is kept in the annotation
+		wantKept bool
+	}{
+		{
+			name: "representable in v1",
+			spec: v2.AttackSpec{
+				Parallelism: 2,
+				Scenario:    "GET http://example.com/",
+				Option: v2.VegetaOption{
+					Duration: &metaV1.Duration{Duration: 30 * time.Second},
+					Rate:     rateOf(intstr.FromInt(10)),
+					Workers:  &workers,
+				},
+			},
+			want: AttackSpec{
+				Parallelism: 2,
+				Scenario:    "GET http://example.com/",
+				Option: VegetaOption{
+					Duration:  "30s",
+					Keepalive: true,
+					Rate:      10,
+					Workers:   4,
+				},
+			},
+		},
+		{
+			name: "per minute rates and v2 only fields",
+			spec: v2.AttackSpec{
+				Scenario: "GET http://example.com/",
+				Option: v2.VegetaOption{
+					Duration: &metaV1.Duration{Duration: 1500 * time.Millisecond},
+					Rate:     rateOf(intstr.FromString("20/1m")),
+				},
+				Stages: []v2.Stage{
+					{Duration: metaV1.Duration{Duration: time.Minute}, TargetRate: rateOf(intstr.FromString("90/1m"))},
+				},
+				Bodies: []v2.Body{{Name: "payload.json", Data: "{}"}},
+			},
+			want: AttackSpec{
+				Scenario: "GET http://example.com/",
+				Option: VegetaOption{
+					Duration:  "1s",
+					Keepalive: true,
+					Rate:      1,
+				},
+				Stages: []Stage{
+					{Duration: "60s", TargetRate: intPtr(2)},
+				},
+			},
+			wantKept: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := &v2.Attack{
+				ObjectMeta: metaV1.ObjectMeta{Name: "attack", Namespace: "default"},
+				Spec:       tt.spec,
+			}
+
+			var attack Attack
+			if err := attack.ConvertFrom(hub); err != nil {
+				t.Fatalf("ConvertFrom() error = %v", err)
+			}
+			if !equality.Semantic.DeepEqual(attack.Spec, tt.want) {
+				t.Errorf("ConvertFrom() spec = %+v, want %+v", attack.Spec, tt.want)
+			}
+			if _, ok := attack.Annotations[v2SpecAnnotation]; ok != tt.wantKept {
+				t.Errorf("ConvertFrom() kept the spec = %v, want %v", ok, tt.wantKept)
+			}
+			if _, ok := hub.Annotations[v2SpecAnnotation]; ok {
+				t.Errorf("ConvertFrom() changed the annotations of the hub")
+			}
+
+			var restored v2.Attack
+			if err := attack.ConvertTo(&restored); err != nil {
+				t.Fatalf("ConvertTo() error = %v", err)
+			}
+			if !equality.Semantic.DeepEqual(restored.Spec, tt.spec) {
+				t.Errorf("ConvertTo() spec = %+v, want %+v", restored.Spec, tt.spec)
+			}
+			if _, ok := restored.Annotations[v2SpecAnnotation]; ok {
+				t.Errorf("ConvertTo() left the annotation %s", v2SpecAnnotation)
+			}
+		})
+	}
+}
+
+func TestAttackConversionChangedInV1(t *testing.T) {
+	hub := &v2.Attack{
+		Spec: v2.AttackSpec{
+			Scenario: "GET http://example.com/",
+			Option: v2.VegetaOption{
+				Rate: rateOf(intstr.FromString("20/1m")),
+			},
+			Bodies: []v2.Body{{Name: "payload.json", Data: "{}"}},
+		},
+	}
+
+	var attack Attack
+	if err := attack.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	// The kept spec is discarded once the spec is changed in v1, since it may conflict with the change
+	attack.Spec.Option.Rate = 5
+
+	var restored v2.Attack
+	if err := attack.ConvertTo(&restored); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	want := v2.AttackSpec{
+		Scenario: "GET http://example.com/",
+		Option: v2.VegetaOption{
+			Rate: rateOf(intstr.FromInt(5)),
+		},
+	}
+	if !equality.Semantic.DeepEqual(restored.Spec, want) {
+		t.Errorf("ConvertTo() spec = %+v, want %+v", restored.Spec, want)
+	}
+	if _, ok := restored.Annotations[v2SpecAnnotation]; ok {
+		t.Errorf("ConvertTo() left the annotation %s", v2SpecAnnotation)
+	}
+}
+
+func TestCronAttackConversionRoundTrip(t *testing.T) {
+	hub := &v2.CronAttack{
+		ObjectMeta: metaV1.ObjectMeta{Name: "cron-attack", Namespace: "default"},
+		Spec: v2.CronAttackSpec{
+			Schedule:          "*/5 * * * *",
+			ConcurrencyPolicy: v2.ForbidConcurrent,
+			AttackTemplate: v2.AttackTemplateSpec{
+				ObjectMeta: metaV1.ObjectMeta{Labels: map[string]string{"app": "attack"}},
+				Spec: v2.AttackSpec{
+					Scenario: "GET http://example.com/",
+					Option: v2.VegetaOption{
+						Rate: rateOf(intstr.FromString("20/1m")),
+					},
+				},
+			},
+		},
+	}
+
+	var cronAttack CronAttack
+	if err := cronAttack.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if cronAttack.Spec.AttackTemplate.Spec.Option.Rate != 1 {
+		t.Errorf("ConvertFrom() rate = %d, want 1", cronAttack.Spec.AttackTemplate.Spec.Option.Rate)
+	}
+	if _, ok := cronAttack.Annotations[v2SpecAnnotation]; !ok {
+		t.Errorf("ConvertFrom() did not keep the spec in the annotation %s", v2SpecAnnotation)
+	}
+
+	var restored v2.CronAttack
+	if err := cronAttack.ConvertTo(&restored); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if !equality.Semantic.DeepEqual(restored.Spec, hub.Spec) {
+		t.Errorf("ConvertTo() spec = %+v, want %+v", restored.Spec, hub.Spec)
+	}
+	if _, ok := restored.Annotations[v2SpecAnnotation]; ok {
+		t.Errorf("ConvertTo() left the annotation %s", v2SpecAnnotation)
+	}
+}
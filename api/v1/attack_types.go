@@ -13,13 +13,92 @@ type AttackSpec struct {
 	Parallelism int32 `json:"parallelism,omitempty"`
 	// Scenario of Attack
 	// More info: https://github.com/tsenart/vegeta#http-format
-	Scenario string `json:"scenario"`
+	// +optional
+	Scenario string `json:"scenario,omitempty"`
+	// Source for the scenario of Attack, which is used instead of Scenario.
+	// The referenced object is mounted into the attack pods directly, and the attack is run again when it changes.
+	// +optional
+	ScenarioFrom *ScenarioSource `json:"scenarioFrom,omitempty"`
 	// +kubebuilder:validation:Enum=text;json
 	// +kubebuilder:default=text
 	Output              string              `json:"output,omitempty"`
 	Option              VegetaOption        `json:"option,omitempty"`
 	Template            Template            `json:"template,omitempty"`
 	AttackContainerSpec AttackContainerSpec `json:"attackContainerSpec,omitempty"`
+	// Specifies how to apply spec changes that require recreating the attack job.
+	// Valid values are:
+	// - "Forbid" (default): postpones replacing the job until the running attack has finished;
+	// - "Restart": deletes the running job and starts the attack again
+	// +kubebuilder:default=Forbid
+	ReplacePolicy ReplacePolicy `json:"replacePolicy,omitempty"`
+	// Thresholds that the report of the attack must satisfy to pass
+	Thresholds *Thresholds `json:"thresholds,omitempty"`
+	// Stages of the load profile, which are run back to back in a single attack.
+	// The duration of option is ignored when stages are specified, and the rate of option is the initial rate.
+	// +optional
+	Stages []Stage `json:"stages,omitempty"`
+}
+
+// Stage defines a step of the load profile
+type Stage struct {
+	// Duration of the stage
+	// +kubebuilder:validation:Pattern=^\d+s$
+	Duration string `json:"duration"`
+	// Constant number of requests per second during the stage.
+	// The rate at the end of the previous stage is kept when neither rate nor targetRate is specified.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Rate int `json:"rate,omitempty"`
+	// Number of requests per second at the end of the stage, which is linearly interpolated from
+	// the rate at the end of the previous stage. Only one of rate and targetRate may be specified.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetRate *int `json:"targetRate,omitempty"`
+}
+
+// Thresholds defines the SLO evaluated against the report of the attack
+type Thresholds struct {
+	// Maximum 99th percentile of latencies
+	MaxLatencyP99 *metaV1.Duration `json:"maxLatencyP99,omitempty"`
+	// Maximum 95th percentile of latencies
+	MaxLatencyP95 *metaV1.Duration `json:"maxLatencyP95,omitempty"`
+	// Maximum mean of latencies
+	MaxLatencyMean *metaV1.Duration `json:"maxLatencyMean,omitempty"`
+	// Minimum ratio of successful requests, in [0, 1]
+	// +kubebuilder:validation:Pattern=^(0(\.\d+)?|1(\.0+)?)$
+	MinSuccessRatio string `json:"minSuccessRatio,omitempty"`
+	// Minimum throughput, the rate of successful requests per second
+	// +kubebuilder:validation:Pattern=^\d+(\.\d+)?$
+	MinThroughput string `json:"minThroughput,omitempty"`
+	// Status codes allowed in responses, 0 represents an error without response
+	AllowedStatusCodes []int32 `json:"allowedStatusCodes,omitempty"`
+	// Maximum number of unsuccessful requests
+	// +kubebuilder:validation:Minimum=0
+	MaxErrors *int64 `json:"maxErrors,omitempty"`
+}
+
+// ReplacePolicy describes how the attack job is replaced when its pod template changes.
+// Only one of the following replace policies may be specified.
+// +kubebuilder:validation:Enum=Forbid;Restart
+type ReplacePolicy string
+
+const (
+	// ForbidReplacePolicy postpones replacing the job until the running attack has finished
+	ForbidReplacePolicy ReplacePolicy = "Forbid"
+
+	// RestartReplacePolicy deletes the running job and starts the attack again
+	RestartReplacePolicy ReplacePolicy = "Restart"
+)
+
+// ScenarioSource represents a source for the scenario of Attack.
+// Only one of its fields may be set.
+type ScenarioSource struct {
+	// Selects a key of a ConfigMap in the namespace of Attack
+	// +optional
+	ConfigMapKeyRef *v1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// Selects a key of a Secret in the namespace of Attack
+	// +optional
+	SecretKeyRef *v1.SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
 
 // Additional Spec for attack container.
@@ -29,8 +108,133 @@ type AttackContainerSpec struct {
 	Resources v1.ResourceRequirements `json:"resources,omitempty" protobuf:"bytes,8,opt,name=resources"`
 }
 
+// AttackPhase is a label for the condition of an attack at the current time
+type AttackPhase string
+
+const (
+	// AttackScheduled means the attack is waiting for startAt to create its job
+	AttackScheduled AttackPhase = "Scheduled"
+	// AttackPending means the attack has been accepted but its pods are not running yet
+	AttackPending AttackPhase = "Pending"
+	// AttackRunning means at least one attack pod is running
+	AttackRunning AttackPhase = "Running"
+	// AttackSucceeded means the attack job has completed successfully
+	AttackSucceeded AttackPhase = "Succeeded"
+	// AttackFailed means the attack job has failed
+	AttackFailed AttackPhase = "Failed"
+	// AttackAborted means the attack has been stopped early since one of the abort conditions was met
+	AttackAborted AttackPhase = "Aborted"
+	// AttackSuspended means the attack has been stopped by suspend, and is started again when it is resumed
+	AttackSuspended AttackPhase = "Suspended"
+	// AttackCancelled means the attack has been stopped by the cancel annotation
+	AttackCancelled AttackPhase = "Cancelled"
+)
+
+const (
+	// AttackReady is True when all attack pods are running
+	AttackReady = "Ready"
+	// AttackComplete is True when the attack job has completed successfully
+	AttackComplete = "Complete"
+	// AttackFailure is True when the attack job has failed
+	AttackFailure = "Failed"
+	// AttackPassed is True when the report of the finished attack satisfies the thresholds
+	AttackPassed = "Passed"
+	// AttackScenarioAvailable is True when the scenario or the object referenced by scenarioFrom is available
+	AttackScenarioAvailable = "ScenarioAvailable"
+)
+
 // AttackStatus defines the observed state of Attack
-type AttackStatus struct{}
+type AttackStatus struct {
+	// Phase of Attack
+	// +kubebuilder:validation:Enum=Scheduled;Pending;Running;Succeeded;Failed;Aborted;Suspended;Cancelled
+	Phase AttackPhase `json:"phase,omitempty"`
+	// Conditions represent the latest available observations of Attack
+	// +listType=map
+	// +listMapKey=type
+	Conditions []Condition `json:"conditions,omitempty"`
+	// ObservedGeneration is the most recent generation observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Time when the attack job was acknowledged by the job controller
+	StartTime *metaV1.Time `json:"startTime,omitempty"`
+	// Time when the attack job was completed or failed
+	CompletionTime *metaV1.Time `json:"completionTime,omitempty"`
+	// The number of actively running attack pods
+	Active int32 `json:"active,omitempty"`
+	// The number of attack pods which reached phase Succeeded
+	Succeeded int32 `json:"succeeded,omitempty"`
+	// The number of attack pods which reached phase Failed
+	Failed int32 `json:"failed,omitempty"`
+	// Report computed from the raw results of all attack pods as a single attack
+	Report *Report `json:"report,omitempty"`
+	// Observed state of each attack pod
+	Pods []AttackPodStatus `json:"pods,omitempty"`
+	// Vegeta options applied to each attack pod, which are divided from option when rateMode is total
+	PodOption *PodOption `json:"podOption,omitempty"`
+	// Reports of each stage computed from the raw results of all attack pods
+	Stages []StageStatus `json:"stages,omitempty"`
+}
+
+// StageStatus defines the observed state of a stage
+type StageStatus struct {
+	// Name of the stage, which is "stage-" followed by its index in spec
+	Name string `json:"name"`
+	// Report computed from the raw results of all attack pods in the stage
+	Report *Report `json:"report,omitempty"`
+}
+
+// AttackPodStatus defines the observed state of an attack pod
+type AttackPodStatus struct {
+	// Name of the pod
+	Name string `json:"name"`
+	// Phase of the pod
+	Phase v1.PodPhase `json:"phase,omitempty"`
+	// Report of vegeta emitted by the pod
+	Report *Report `json:"report,omitempty"`
+	// A human readable message indicating why the report could not be collected
+	Message string `json:"message,omitempty"`
+}
+
+// Report defines the metrics of vegeta report
+// More info: https://github.com/tsenart/vegeta#report-command
+type Report struct {
+	// Total number of requests
+	Requests int64 `json:"requests"`
+	// Rate of sent requests per second
+	Rate string `json:"rate"`
+	// Rate of successful requests per second
+	Throughput string `json:"throughput"`
+	// Ratio of non-error responses, in [0, 1]
+	Success string `json:"success"`
+	// Time taken from the first request to the last request
+	Duration metaV1.Duration `json:"duration"`
+	// Time taken to wait for the response of the last request
+	Wait metaV1.Duration `json:"wait"`
+	// Latency distribution of requests
+	Latencies Latencies `json:"latencies"`
+	// Bytes received in response bodies
+	BytesIn Bytes `json:"bytesIn"`
+	// Bytes sent in request bodies
+	BytesOut Bytes `json:"bytesOut"`
+	// Number of responses for each status code, "0" represents an error without response
+	StatusCodes map[string]int64 `json:"statusCodes,omitempty"`
+	// Set of unique errors returned by the targets
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Latencies defines the latency distribution of requests
+type Latencies struct {
+	Mean metaV1.Duration `json:"mean"`
+	P50  metaV1.Duration `json:"p50"`
+	P95  metaV1.Duration `json:"p95"`
+	P99  metaV1.Duration `json:"p99"`
+	Max  metaV1.Duration `json:"max"`
+}
+
+// Bytes defines the amount of transferred bytes
+type Bytes struct {
+	Total int64  `json:"total"`
+	Mean  string `json:"mean"`
+}
 
 // VegetaOption defines the vegeta options
 type VegetaOption struct {
@@ -51,6 +255,12 @@ type VegetaOption struct {
 	// More info: https://github.com/tsenart/vegeta#usage-manual
 	// +kubebuilder:validation:Minimum=1
 	Rate int `json:"rate,omitempty"`
+	// Specifies how rate, workers and connections are applied to the attack pods.
+	// Valid values are:
+	// - "perPod" (default): every pod uses them as they are, so the total rate is multiplied by parallelism;
+	// - "total": they are divided across the pods, so that the pods send the configured rate in total
+	// +kubebuilder:default=perPod
+	RateMode RateMode `json:"rateMode,omitempty"`
 	// Requests timeout (default 30s)
 	// More info: https://github.com/tsenart/vegeta#usage-manual
 	// +kubebuilder:validation:Pattern=^\d+s$
@@ -65,6 +275,29 @@ type VegetaOption struct {
 	Format string `json:"format,omitempty"`
 }
 
+// RateMode describes how the rate of Attack is applied to the attack pods.
+// Only one of the following rate modes may be specified.
+// +kubebuilder:validation:Enum=perPod;total
+type RateMode string
+
+const (
+	// PerPodRateMode applies the rate to every attack pod
+	PerPodRateMode RateMode = "perPod"
+
+	// TotalRateMode divides the rate across the attack pods
+	TotalRateMode RateMode = "total"
+)
+
+// PodOption defines the vegeta options effectively applied to each attack pod
+type PodOption struct {
+	// Value of -rate, which may be a fraction such as "10/4s". Empty when stages are specified
+	Rate string `json:"rate,omitempty"`
+	// Value of -workers
+	Workers int `json:"workers,omitempty"`
+	// Value of -connections
+	Connections int `json:"connections,omitempty"`
+}
+
 // Template defines the pod template generated by job
 type Template struct {
 	// Standard object's metadata.
@@ -82,6 +315,15 @@ type Spec struct {
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Active",type="integer",JSONPath=".status.active"
+// +kubebuilder:printcolumn:name="Succeeded",type="integer",JSONPath=".status.succeeded"
+// +kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failed"
+// +kubebuilder:printcolumn:name="Requests",type="integer",JSONPath=".status.report.requests"
+// +kubebuilder:printcolumn:name="Success",type="string",JSONPath=".status.report.success"
+// +kubebuilder:printcolumn:name="Passed",type="string",JSONPath=".status.conditions[?(@.type==\"Passed\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Attack is the schema for the attacks API
 type Attack struct {
@@ -6,6 +6,7 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -15,7 +16,7 @@ func (in *Attack) DeepCopyInto(out *Attack) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Attack.
@@ -84,12 +85,49 @@ func (in *AttackList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AttackPodStatus) DeepCopyInto(out *AttackPodStatus) {
+	*out = *in
+	if in.Report != nil {
+		in, out := &in.Report, &out.Report
+		*out = new(Report)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AttackPodStatus.
+func (in *AttackPodStatus) DeepCopy() *AttackPodStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AttackPodStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AttackSpec) DeepCopyInto(out *AttackSpec) {
 	*out = *in
+	if in.ScenarioFrom != nil {
+		in, out := &in.ScenarioFrom, &out.ScenarioFrom
+		*out = new(ScenarioSource)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Option = in.Option
 	in.Template.DeepCopyInto(&out.Template)
 	in.AttackContainerSpec.DeepCopyInto(&out.AttackContainerSpec)
+	if in.Thresholds != nil {
+		in, out := &in.Thresholds, &out.Thresholds
+		*out = new(Thresholds)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]Stage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AttackSpec.
@@ -105,6 +143,45 @@ func (in *AttackSpec) DeepCopy() *AttackSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AttackStatus) DeepCopyInto(out *AttackStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Report != nil {
+		in, out := &in.Report, &out.Report
+		*out = new(Report)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]AttackPodStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodOption != nil {
+		in, out := &in.PodOption, &out.PodOption
+		*out = new(PodOption)
+		**out = **in
+	}
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]StageStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AttackStatus.
@@ -117,6 +194,265 @@ func (in *AttackStatus) DeepCopy() *AttackStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AttackTemplateSpec) DeepCopyInto(out *AttackTemplateSpec) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AttackTemplateSpec.
+func (in *AttackTemplateSpec) DeepCopy() *AttackTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AttackTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Bytes) DeepCopyInto(out *Bytes) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Bytes.
+func (in *Bytes) DeepCopy() *Bytes {
+	if in == nil {
+		return nil
+	}
+	out := new(Bytes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Condition) DeepCopyInto(out *Condition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
+func (in *Condition) DeepCopy() *Condition {
+	if in == nil {
+		return nil
+	}
+	out := new(Condition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronAttack) DeepCopyInto(out *CronAttack) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronAttack.
+func (in *CronAttack) DeepCopy() *CronAttack {
+	if in == nil {
+		return nil
+	}
+	out := new(CronAttack)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CronAttack) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronAttackList) DeepCopyInto(out *CronAttackList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CronAttack, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronAttackList.
+func (in *CronAttackList) DeepCopy() *CronAttackList {
+	if in == nil {
+		return nil
+	}
+	out := new(CronAttackList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CronAttackList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronAttackSpec) DeepCopyInto(out *CronAttackSpec) {
+	*out = *in
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		*out = new(bool)
+		**out = **in
+	}
+	in.AttackTemplate.DeepCopyInto(&out.AttackTemplate)
+	if in.SuccessfulAttacksHistoryLimit != nil {
+		in, out := &in.SuccessfulAttacksHistoryLimit, &out.SuccessfulAttacksHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedAttacksHistoryLimit != nil {
+		in, out := &in.FailedAttacksHistoryLimit, &out.FailedAttacksHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronAttackSpec.
+func (in *CronAttackSpec) DeepCopy() *CronAttackSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CronAttackSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronAttackStatus) DeepCopyInto(out *CronAttackStatus) {
+	*out = *in
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = make([]corev1.ObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronAttackStatus.
+func (in *CronAttackStatus) DeepCopy() *CronAttackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CronAttackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Latencies) DeepCopyInto(out *Latencies) {
+	*out = *in
+	out.Mean = in.Mean
+	out.P50 = in.P50
+	out.P95 = in.P95
+	out.P99 = in.P99
+	out.Max = in.Max
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Latencies.
+func (in *Latencies) DeepCopy() *Latencies {
+	if in == nil {
+		return nil
+	}
+	out := new(Latencies)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodOption) DeepCopyInto(out *PodOption) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodOption.
+func (in *PodOption) DeepCopy() *PodOption {
+	if in == nil {
+		return nil
+	}
+	out := new(PodOption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Report) DeepCopyInto(out *Report) {
+	*out = *in
+	out.Duration = in.Duration
+	out.Wait = in.Wait
+	out.Latencies = in.Latencies
+	out.BytesIn = in.BytesIn
+	out.BytesOut = in.BytesOut
+	if in.StatusCodes != nil {
+		in, out := &in.StatusCodes, &out.StatusCodes
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Report.
+func (in *Report) DeepCopy() *Report {
+	if in == nil {
+		return nil
+	}
+	out := new(Report)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScenarioSource) DeepCopyInto(out *ScenarioSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScenarioSource.
+func (in *ScenarioSource) DeepCopy() *ScenarioSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ScenarioSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Spec) DeepCopyInto(out *Spec) {
 	*out = *in
@@ -139,6 +475,46 @@ func (in *Spec) DeepCopy() *Spec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Stage) DeepCopyInto(out *Stage) {
+	*out = *in
+	if in.TargetRate != nil {
+		in, out := &in.TargetRate, &out.TargetRate
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Stage.
+func (in *Stage) DeepCopy() *Stage {
+	if in == nil {
+		return nil
+	}
+	out := new(Stage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StageStatus) DeepCopyInto(out *StageStatus) {
+	*out = *in
+	if in.Report != nil {
+		in, out := &in.Report, &out.Report
+		*out = new(Report)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StageStatus.
+func (in *StageStatus) DeepCopy() *StageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Template) DeepCopyInto(out *Template) {
 	*out = *in
@@ -156,6 +532,46 @@ func (in *Template) DeepCopy() *Template {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Thresholds) DeepCopyInto(out *Thresholds) {
+	*out = *in
+	if in.MaxLatencyP99 != nil {
+		in, out := &in.MaxLatencyP99, &out.MaxLatencyP99
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxLatencyP95 != nil {
+		in, out := &in.MaxLatencyP95, &out.MaxLatencyP95
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxLatencyMean != nil {
+		in, out := &in.MaxLatencyMean, &out.MaxLatencyMean
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.AllowedStatusCodes != nil {
+		in, out := &in.AllowedStatusCodes, &out.AllowedStatusCodes
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxErrors != nil {
+		in, out := &in.MaxErrors, &out.MaxErrors
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Thresholds.
+func (in *Thresholds) DeepCopy() *Thresholds {
+	if in == nil {
+		return nil
+	}
+	out := new(Thresholds)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VegetaOption) DeepCopyInto(out *VegetaOption) {
 	*out = *in
@@ -0,0 +1,750 @@
+package v2
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// AttackSpec defines the desired state of Attack
+type AttackSpec struct {
+	// Parallelism of Attack
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	Parallelism int32 `json:"parallelism,omitempty"`
+	// Scenario of Attack
+	// More info: https://github.com/tsenart/vegeta#http-format
+	// +optional
+	Scenario string `json:"scenario,omitempty"`
+	// Source for the scenario of Attack, which is used instead of Scenario.
+	// The referenced object is mounted into the attack pods directly, and the attack is run again when it changes.
+	// +optional
+	ScenarioFrom *ScenarioSource `json:"scenarioFrom,omitempty"`
+	// +kubebuilder:validation:Enum=text;json
+	// +kubebuilder:default=text
+	Output              string              `json:"output,omitempty"`
+	Option              VegetaOption        `json:"option,omitempty"`
+	Template            Template            `json:"template,omitempty"`
+	AttackContainerSpec AttackContainerSpec `json:"attackContainerSpec,omitempty"`
+	// Specifies how to apply spec changes that require recreating the attack job.
+	// Valid values are:
+	// - "Forbid" (default): postpones replacing the job until the running attack has finished;
+	// - "Restart": deletes the running job and starts the attack again
+	// +kubebuilder:default=Forbid
+	ReplacePolicy ReplacePolicy `json:"replacePolicy,omitempty"`
+	// Thresholds that the report of the attack must satisfy to pass
+	Thresholds *Thresholds `json:"thresholds,omitempty"`
+	// Stages of the load profile, which are run back to back in a single attack.
+	// The duration of option is ignored when stages are specified, and the rate of option is the initial rate.
+	// +optional
+	Stages []Stage `json:"stages,omitempty"`
+	// TLS configuration of the attack, such as client certificates and root CAs
+	// +optional
+	TLS *TLS `json:"tls,omitempty"`
+	// Request headers added to all targets, whose values can be taken from Secrets.
+	// Values from Secrets are passed to the attack pods as environment variables, and are never written into the
+	// scenario ConfigMap.
+	// +optional
+	Headers []HeaderVar `json:"headers,omitempty"`
+	// Request bodies, each of which is mounted at "/var/lib/vegeta-bodies/<name>" for targets in http format to
+	// reference by "@/var/lib/vegeta-bodies/<name>"
+	// +optional
+	Bodies []Body `json:"bodies,omitempty"`
+	// Reports generated from the raw results of all attack pods when the attack has finished, in addition to output.
+	// They are stored in the "<name>-report" ConfigMap, followed by "<name>-report-1" and so on when they exceed
+	// the limit of ConfigMap, and are linked from status.artifacts.
+	// A report which exceeds the limit by itself is not stored, and the ReportsStored condition becomes False.
+	// +optional
+	Reports []ReportOutput `json:"reports,omitempty"`
+	// Conditions to stop the running attack early, which are evaluated against the live results of all attack pods.
+	// The attack is aborted when any of them is met, and the results until then are kept.
+	// +optional
+	AbortConditions []AbortCondition `json:"abortConditions,omitempty"`
+	// Suspend stops the running attack pods, keeping the job and the pods with their logs for inspection.
+	// Setting it back to false starts the attack again from the beginning.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// Seconds after the attack has finished to clean it up according to cleanupPolicy.
+	// The default of the controller is used when it is not specified.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+	// Specifies what is deleted when ttlSecondsAfterFinished has passed.
+	// Valid values are:
+	// - "Job" (default): deletes the job, its pods and the config maps for them, and keeps Attack with its status;
+	// - "Attack": deletes Attack itself along with all of them.
+	// The report config maps are kept in both cases.
+	// +kubebuilder:default=Job
+	CleanupPolicy CleanupPolicy `json:"cleanupPolicy,omitempty"`
+	// Start barrier which makes all attack pods begin firing at the same instant
+	// +optional
+	StartBarrier *StartBarrier `json:"startBarrier,omitempty"`
+	// Time to start the attack, until which the job is not created
+	// +optional
+	StartAt *metaV1.Time `json:"startAt,omitempty"`
+	// Time by which the attack must finish.
+	// The attack pods stop by themselves at the deadline, and the attack fails with the results until then.
+	// The job is never created after the deadline.
+	// +optional
+	Deadline *metaV1.Time `json:"deadline,omitempty"`
+	// Placement of the attack pods on nodes, which spreads them over nodes by default
+	// +optional
+	Placement *Placement `json:"placement,omitempty"`
+}
+
+// StartBarrier holds the attack pods until startAt, which is written into the "<name>-start" ConfigMap.
+// startAt is set when all attack pods are ready, or is the deadline after timeout since the job was created.
+type StartBarrier struct {
+	// How long to wait for all attack pods to be ready since the job was created (default 5m).
+	// The pods ready by then start without waiting for the rest.
+	// +optional
+	Timeout *metaV1.Duration `json:"timeout,omitempty"`
+	// Delay of startAt since all attack pods are ready (default 90s).
+	// It must be long enough for kubelet to update the mounted ConfigMap, which depends on its sync period.
+	// +optional
+	Delay *metaV1.Duration `json:"delay,omitempty"`
+}
+
+// Placement describes how the attack pods are placed on nodes.
+// The affinity and the topology spread constraints in the pod template take precedence over those of the strategy.
+type Placement struct {
+	// Strategy to place the attack pods (default PerNode)
+	// +optional
+	Strategy PlacementStrategy `json:"strategy,omitempty"`
+	// Whether the attack pods must be placed by the strategy, otherwise they are placed by it as far as possible.
+	// The attack pods which can not be placed remain pending.
+	// PerZone is required only by topology spread constraints, which need EvenPodsSpread feature gate before Kubernetes 1.18.
+	// +optional
+	Required bool `json:"required,omitempty"`
+}
+
+// PlacementStrategy describes how the attack pods are placed on nodes.
+// Only one of the following placement strategies may be specified.
+// +kubebuilder:validation:Enum=PerNode;PerZone;Packed;Custom
+type PlacementStrategy string
+
+const (
+	// PerNodePlacementStrategy spreads the attack pods over nodes by pod anti-affinity
+	PerNodePlacementStrategy PlacementStrategy = "PerNode"
+
+	// PerZonePlacementStrategy spreads the attack pods evenly over zones by topology spread constraints,
+	// which need EvenPodsSpread feature gate before Kubernetes 1.18.
+	// The pods are also spread over zones by preferred pod anti-affinity, which works without the feature gate.
+	PerZonePlacementStrategy PlacementStrategy = "PerZone"
+
+	// PackedPlacementStrategy places the attack pods together on as few nodes as possible by pod affinity
+	PackedPlacementStrategy PlacementStrategy = "Packed"
+
+	// CustomPlacementStrategy places the attack pods only by the affinity and the topology spread constraints
+	// in the pod template
+	CustomPlacementStrategy PlacementStrategy = "Custom"
+)
+
+// AbortCondition is met when any of its limits is exceeded over the sliding window for the duration
+type AbortCondition struct {
+	// Maximum ratio of unsuccessful requests, in [0, 1]
+	// +kubebuilder:validation:Pattern=^(0(\.\d+)?|1(\.0+)?)$
+	// +optional
+	MaxErrorRatio string `json:"maxErrorRatio,omitempty"`
+	// Maximum 99th percentile of latencies
+	// +optional
+	MaxLatencyP99 *metaV1.Duration `json:"maxLatencyP99,omitempty"`
+	// Maximum 95th percentile of latencies
+	// +optional
+	MaxLatencyP95 *metaV1.Duration `json:"maxLatencyP95,omitempty"`
+	// Maximum mean of latencies
+	// +optional
+	MaxLatencyMean *metaV1.Duration `json:"maxLatencyMean,omitempty"`
+	// Period of the latest results which the limits are evaluated over (default 30s)
+	// +optional
+	Window *metaV1.Duration `json:"window,omitempty"`
+	// How long the limit must be exceeded before the attack is aborted (default 0s)
+	// +optional
+	For *metaV1.Duration `json:"for,omitempty"`
+}
+
+// Stage defines a step of the load profile
+type Stage struct {
+	// Duration of the stage [0 = forever]
+	Duration metaV1.Duration `json:"duration"`
+	// Constant rate during the stage in the same syntax as rate of option, including zero with maxWorkers.
+	// The rate at the end of the previous stage is kept when neither rate nor targetRate is specified.
+	// +optional
+	Rate *intstr.IntOrString `json:"rate,omitempty"`
+	// Rate at the end of the stage in the same syntax as rate of option, which is reached in steps from the rate at
+	// the end of the previous stage, which must not be zero. Only one of rate and targetRate may be specified.
+	// The stage runs as 10 steps of equal duration and constant rate, which is the rate of a linear ramp at the
+	// midpoint of each step, so that its duration must be at least 10s. Each step is a separate run of vegeta,
+	// which waits for the responses in flight before the next step starts, so that there is a short gap without
+	// new requests between the steps.
+	// +optional
+	TargetRate *intstr.IntOrString `json:"targetRate,omitempty"`
+}
+
+// RampSteps is the number of steps of constant rate which a stage with targetRate runs as
+const RampSteps = 10
+
+// Thresholds defines the SLO evaluated against the report of the attack
+type Thresholds struct {
+	// Maximum 99th percentile of latencies
+	MaxLatencyP99 *metaV1.Duration `json:"maxLatencyP99,omitempty"`
+	// Maximum 95th percentile of latencies
+	MaxLatencyP95 *metaV1.Duration `json:"maxLatencyP95,omitempty"`
+	// Maximum mean of latencies
+	MaxLatencyMean *metaV1.Duration `json:"maxLatencyMean,omitempty"`
+	// Minimum ratio of successful requests, in [0, 1]
+	// +kubebuilder:validation:Pattern=^(0(\.\d+)?|1(\.0+)?)$
+	MinSuccessRatio string `json:"minSuccessRatio,omitempty"`
+	// Minimum throughput, the rate of successful requests per second
+	// +kubebuilder:validation:Pattern=^\d+(\.\d+)?$
+	MinThroughput string `json:"minThroughput,omitempty"`
+	// Status codes allowed in responses, 0 represents an error without response
+	AllowedStatusCodes []int32 `json:"allowedStatusCodes,omitempty"`
+	// Maximum number of unsuccessful requests
+	// +kubebuilder:validation:Minimum=0
+	MaxErrors *int64 `json:"maxErrors,omitempty"`
+}
+
+// ReplacePolicy describes how the attack job is replaced when its pod template changes.
+// Only one of the following replace policies may be specified.
+// +kubebuilder:validation:Enum=Forbid;Restart
+type ReplacePolicy string
+
+const (
+	// ForbidReplacePolicy postpones replacing the job until the running attack has finished
+	ForbidReplacePolicy ReplacePolicy = "Forbid"
+
+	// RestartReplacePolicy deletes the running job and starts the attack again
+	RestartReplacePolicy ReplacePolicy = "Restart"
+)
+
+// CleanupPolicy describes what is deleted when the finished attack is cleaned up.
+// Only one of the following cleanup policies may be specified.
+// +kubebuilder:validation:Enum=Job;Attack
+type CleanupPolicy string
+
+const (
+	// JobCleanupPolicy deletes the job, its pods and the config maps for them
+	JobCleanupPolicy CleanupPolicy = "Job"
+
+	// AttackCleanupPolicy deletes Attack itself
+	AttackCleanupPolicy CleanupPolicy = "Attack"
+)
+
+// ScenarioSource represents a source for the scenario of Attack.
+// Only one of its fields may be set.
+type ScenarioSource struct {
+	// Selects a key of a ConfigMap in the namespace of Attack
+	// +optional
+	ConfigMapKeyRef *v1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// Selects a key of a Secret in the namespace of Attack
+	// +optional
+	SecretKeyRef *v1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// HeaderVar represents a request header, whose value is given literally or taken from a Secret.
+type HeaderVar struct {
+	// Name of the header
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Value of the header
+	// +optional
+	Value string `json:"value,omitempty"`
+	// Source for the value of the header, which is used instead of Value
+	// +optional
+	ValueFrom *HeaderVarSource `json:"valueFrom,omitempty"`
+}
+
+// HeaderVarSource represents a source for the value of a header.
+type HeaderVarSource struct {
+	// Selects a key of a Secret in the namespace of Attack
+	SecretKeyRef *v1.SecretKeySelector `json:"secretKeyRef"`
+}
+
+// ReportType is the type of report
+type ReportType string
+
+const (
+	// TextReportType is the same as `vegeta report -type=text`
+	TextReportType ReportType = "text"
+	// JSONReportType is the same as `vegeta report -type=json`
+	JSONReportType ReportType = "json"
+	// HistReportType is `vegeta report -type=hist[buckets]` of each attack pod
+	HistReportType ReportType = "hist"
+	// HDRPlotReportType is `vegeta report -type=hdrplot` of each attack pod
+	HDRPlotReportType ReportType = "hdrplot"
+	// PlotReportType is `vegeta plot` of each attack pod
+	PlotReportType ReportType = "plot"
+)
+
+// ReportOutput represents a report generated from the results of the attack.
+// text and json are generated from the merged results of all attack pods, while hist, hdrplot and plot are generated
+// by vegeta in each attack pod, since they can not be merged across pods.
+type ReportOutput struct {
+	// Type of the report [text, json, hist, hdrplot, plot]
+	// More info: https://github.com/tsenart/vegeta#report-command
+	// +kubebuilder:validation:Enum=text;json;hist;hdrplot;plot
+	Type ReportType `json:"type"`
+	// Key of the report in the ConfigMap (default "<type>" followed by the extension, e.g. "plot.html")
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Bounds of the buckets of hist in ascending order, where the last bucket is unbounded, e.g. ["0s", "10ms", "100ms"]
+	// +optional
+	Buckets []metaV1.Duration `json:"buckets,omitempty"`
+	// Title of plot (default "Vegeta Plot")
+	// +optional
+	Title string `json:"title,omitempty"`
+	// Threshold of data points above which the series of plot are downsampled (default 4000)
+	// +kubebuilder:validation:Minimum=3
+	// +optional
+	Threshold *int32 `json:"threshold,omitempty"`
+}
+
+// Key returns the key of the report in the ConfigMap
+func (o *ReportOutput) Key() string {
+	if o.Name != "" {
+		return o.Name
+	}
+	switch o.Type {
+	case JSONReportType:
+		return string(o.Type) + ".json"
+	case PlotReportType:
+		return string(o.Type) + ".html"
+	default:
+		return string(o.Type) + ".txt"
+	}
+}
+
+// BodyMountPath is the directory where the bodies are mounted in the attack pods
+const BodyMountPath = "/var/lib/vegeta-bodies"
+
+// Body represents a request body, whose payload is given inline or taken from a ConfigMap or Secret.
+// Only one of Data, BinaryData and ValueFrom may be set, and the body is empty when none is set.
+type Body struct {
+	// Name of the body, which is the file name in BodyMountPath
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	Name string `json:"name"`
+	// UTF-8 payload of the body
+	// +optional
+	Data string `json:"data,omitempty"`
+	// Binary payload of the body
+	// +optional
+	BinaryData []byte `json:"binaryData,omitempty"`
+	// Source for the payload of the body
+	// +optional
+	ValueFrom *BodySource `json:"valueFrom,omitempty"`
+}
+
+// BodySource represents a source for the payload of a body.
+// Only one of its fields may be set.
+type BodySource struct {
+	// Selects a key of a ConfigMap in the namespace of Attack
+	// +optional
+	ConfigMapKeyRef *v1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// Selects a key of a Secret in the namespace of Attack
+	// +optional
+	SecretKeyRef *v1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// TLS configures the certificates used to attack servers over TLS.
+type TLS struct {
+	// Secret of type kubernetes.io/tls, whose tls.crt and tls.key are presented as the client certificate
+	// +optional
+	ClientCertSecretRef *v1.LocalObjectReference `json:"clientCertSecretRef,omitempty"`
+	// PEM encoded root CAs to verify the servers with, instead of the CAs of the vegeta image
+	// +optional
+	RootCAs *CertificateSource `json:"rootCAs,omitempty"`
+	// Skips verification of the certificates of the servers, which is the same as insecure of option
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// CertificateSource represents a source for PEM encoded certificates.
+// Only one of its fields may be set.
+type CertificateSource struct {
+	// Selects a key of a ConfigMap in the namespace of Attack
+	// +optional
+	ConfigMapKeyRef *v1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// Selects a key of a Secret in the namespace of Attack
+	// +optional
+	SecretKeyRef *v1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// Additional Spec for attack container.
+type AttackContainerSpec struct {
+	// Compute Resources required by this container.
+	// More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/
+	Resources v1.ResourceRequirements `json:"resources,omitempty" protobuf:"bytes,8,opt,name=resources"`
+}
+
+// AttackPhase is a label for the condition of an attack at the current time
+type AttackPhase string
+
+const (
+	// AttackScheduled means the attack is waiting for startAt to create its job
+	AttackScheduled AttackPhase = "Scheduled"
+	// AttackPending means the attack has been accepted but its pods are not running yet
+	AttackPending AttackPhase = "Pending"
+	// AttackRunning means at least one attack pod is running
+	AttackRunning AttackPhase = "Running"
+	// AttackSucceeded means the attack job has completed successfully
+	AttackSucceeded AttackPhase = "Succeeded"
+	// AttackFailed means the attack job has failed
+	AttackFailed AttackPhase = "Failed"
+	// AttackAborted means the attack has been stopped early since one of the abort conditions was met
+	AttackAborted AttackPhase = "Aborted"
+	// AttackSuspended means the attack has been stopped by suspend, and is started again when it is resumed
+	AttackSuspended AttackPhase = "Suspended"
+	// AttackCancelled means the attack has been stopped by the cancel annotation
+	AttackCancelled AttackPhase = "Cancelled"
+)
+
+const (
+	// AttackReady is True when all attack pods are running
+	AttackReady = "Ready"
+	// AttackComplete is True when the attack job has completed successfully
+	AttackComplete = "Complete"
+	// AttackFailure is True when the attack job has failed
+	AttackFailure = "Failed"
+	// AttackPassed is True when the report of the finished attack satisfies the thresholds
+	AttackPassed = "Passed"
+	// AttackScenarioAvailable is True when the scenario or the object referenced by scenarioFrom is available
+	AttackScenarioAvailable = "ScenarioAvailable"
+	// AttackBodiesAvailable is True when the objects referenced by bodies are available
+	AttackBodiesAvailable = "BodiesAvailable"
+	// AttackTLSAvailable is True when the objects referenced by tls are available
+	AttackTLSAvailable = "TLSAvailable"
+	// AttackSpecApplied is False while the running job is not replaced for the changed spec by replacePolicy
+	AttackSpecApplied = "SpecApplied"
+	// AttackOptionsSupported is False when the vegeta image does not support some of the options
+	AttackOptionsSupported = "OptionsSupported"
+	// AttackAbortion is True when the attack has been aborted by one of the abort conditions
+	AttackAbortion = "Aborted"
+	// AttackAbortMonitoringAvailable is False while the results of some running attack pods can not be followed,
+	// in which case abort conditions are evaluated without them
+	AttackAbortMonitoringAvailable = "AbortMonitoringAvailable"
+	// AttackSuspension is True when the attack pods have stopped by suspend
+	AttackSuspension = "Suspended"
+	// AttackCancellation is True when the attack pods have stopped by the cancel annotation
+	AttackCancellation = "Cancelled"
+	// AttackCleanedUp is True when the job of the finished attack has been deleted by ttlSecondsAfterFinished
+	AttackCleanedUp = "CleanedUp"
+	// AttackReportsStored is False when some of the reports are too large to be stored in ConfigMap
+	AttackReportsStored = "ReportsStored"
+	// AttackResultsCollected is False when some results of the attack pods have been lost by log rotation,
+	// in which case the report of the attack is not computed
+	AttackResultsCollected = "ResultsCollected"
+)
+
+// CancelAnnotation cancels the running attack when it is set to a value other than the one the attack was started with,
+// such as the current time
+const CancelAnnotation = "vegeta.kaidotdev.github.io/cancel"
+
+// AttackStatus defines the observed state of Attack
+type AttackStatus struct {
+	// Phase of Attack
+	// +kubebuilder:validation:Enum=Scheduled;Pending;Running;Succeeded;Failed;Aborted;Suspended;Cancelled
+	Phase AttackPhase `json:"phase,omitempty"`
+	// Conditions represent the latest available observations of Attack
+	// +listType=map
+	// +listMapKey=type
+	Conditions []Condition `json:"conditions,omitempty"`
+	// ObservedGeneration is the most recent generation observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Time when the attack job was acknowledged by the job controller
+	StartTime *metaV1.Time `json:"startTime,omitempty"`
+	// Time when the attack job was completed or failed
+	CompletionTime *metaV1.Time `json:"completionTime,omitempty"`
+	// The number of actively running attack pods
+	Active int32 `json:"active,omitempty"`
+	// The number of attack pods which reached phase Succeeded
+	Succeeded int32 `json:"succeeded,omitempty"`
+	// The number of attack pods which reached phase Failed
+	Failed int32 `json:"failed,omitempty"`
+	// Report computed from the raw results of all attack pods as a single attack
+	Report *Report `json:"report,omitempty"`
+	// Observed state of each attack pod
+	Pods []AttackPodStatus `json:"pods,omitempty"`
+	// Vegeta options applied to each attack pod, which are divided from option when rateMode is total
+	PodOption *PodOption `json:"podOption,omitempty"`
+	// Reports of each stage computed from the raw results of all attack pods
+	Stages []StageStatus `json:"stages,omitempty"`
+	// Reports of spec.reports generated when the attack has finished
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+	// Time when the attack pods are released from the start barrier
+	StartAt *metaV1.Time `json:"startAt,omitempty"`
+	// Difference between the first requests of the earliest and the latest attack pods
+	StartSkew *metaV1.Duration `json:"startSkew,omitempty"`
+}
+
+// Artifact is a link to a report stored in a ConfigMap
+type Artifact struct {
+	// Type of the report
+	Type ReportType `json:"type"`
+	// Name of the attack pod which generated the report, which is empty for the report of all attack pods
+	// +optional
+	Pod string `json:"pod,omitempty"`
+	// Key of the report in the ConfigMap, which is prefixed by the name of the pod for the report of an attack pod
+	ConfigMapKeyRef v1.ConfigMapKeySelector `json:"configMapKeyRef"`
+}
+
+// StageStatus defines the observed state of a stage
+type StageStatus struct {
+	// Name of the stage, which is "stage-" followed by its index in spec
+	Name string `json:"name"`
+	// Report computed from the raw results of all attack pods in the stage
+	Report *Report `json:"report,omitempty"`
+}
+
+// AttackPodStatus defines the observed state of an attack pod
+type AttackPodStatus struct {
+	// Name of the pod
+	Name string `json:"name"`
+	// Phase of the pod
+	Phase v1.PodPhase `json:"phase,omitempty"`
+	// Name of the node which the pod has been scheduled to
+	NodeName string `json:"nodeName,omitempty"`
+	// Zone of the node, which is empty when the node has no zone label
+	Zone string `json:"zone,omitempty"`
+	// Report of vegeta emitted by the pod
+	Report *Report `json:"report,omitempty"`
+	// A human readable message indicating why the report could not be collected
+	Message string `json:"message,omitempty"`
+}
+
+// Report defines the metrics of vegeta report
+// More info: https://github.com/tsenart/vegeta#report-command
+type Report struct {
+	// Total number of requests
+	Requests int64 `json:"requests"`
+	// Rate of sent requests per second
+	Rate string `json:"rate"`
+	// Rate of successful requests per second
+	Throughput string `json:"throughput"`
+	// Ratio of non-error responses, in [0, 1]
+	Success string `json:"success"`
+	// Time taken from the first request to the last request
+	Duration metaV1.Duration `json:"duration"`
+	// Time taken to wait for the response of the last request
+	Wait metaV1.Duration `json:"wait"`
+	// Latency distribution of requests
+	Latencies Latencies `json:"latencies"`
+	// Bytes received in response bodies
+	BytesIn Bytes `json:"bytesIn"`
+	// Bytes sent in request bodies
+	BytesOut Bytes `json:"bytesOut"`
+	// Number of responses for each status code, "0" represents an error without response
+	StatusCodes map[string]int64 `json:"statusCodes,omitempty"`
+	// Set of unique errors returned by the targets
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Latencies defines the latency distribution of requests
+type Latencies struct {
+	Mean metaV1.Duration `json:"mean"`
+	P50  metaV1.Duration `json:"p50"`
+	P95  metaV1.Duration `json:"p95"`
+	P99  metaV1.Duration `json:"p99"`
+	Max  metaV1.Duration `json:"max"`
+}
+
+// Bytes defines the amount of transferred bytes
+type Bytes struct {
+	Total int64  `json:"total"`
+	Mean  string `json:"mean"`
+}
+
+// VegetaOption defines the vegeta options.
+// Unset options are not passed to vegeta, so that the defaults of vegeta are used.
+type VegetaOption struct {
+	// Duration of the test [0 = forever]
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	// +kubebuilder:default="10s"
+	Duration *metaV1.Duration `json:"duration,omitempty"`
+	// Max open idle connections per target host (default 10000)
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	// +kubebuilder:validation:Minimum=1
+	Connections *int32 `json:"connections,omitempty"`
+	// Use persistent connections (default true)
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	Keepalive *bool `json:"keepalive,omitempty"`
+	// Number of requests per time unit, either an integer per second or "<requests>/<time unit>" such as "100/1m"
+	// (default 50/1s). Zero sends requests as fast as maxWorkers allow, which must be specified with it.
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	Rate *intstr.IntOrString `json:"rate,omitempty"`
+	// Specifies how rate, workers and connections are applied to the attack pods.
+	// Valid values are:
+	// - "perPod" (default): every pod uses them as they are, so the total rate is multiplied by parallelism;
+	// - "total": they are divided across the pods, so that the pods send the configured rate in total
+	// +kubebuilder:default=perPod
+	RateMode RateMode `json:"rateMode,omitempty"`
+	// Requests timeout (default 30s)
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	Timeout *metaV1.Duration `json:"timeout,omitempty"`
+	// Initial number of workers (default 10)
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	// +kubebuilder:validation:Minimum=1
+	Workers *int32 `json:"workers,omitempty"`
+	// Targets format [http, json] (default "http")
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	// +kubebuilder:validation:Enum=http;json
+	Format string `json:"format,omitempty"`
+	// Request headers added to all targets
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	// +optional
+	Headers []Header `json:"headers,omitempty"`
+	// Ignore invalid server TLS certificates (default false)
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	Insecure *bool `json:"insecure,omitempty"`
+	// Send HTTP/2 requests when supported by the server (default true)
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	HTTP2 *bool `json:"http2,omitempty"`
+	// Send HTTP/2 requests without TLS encryption (default false)
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	H2C *bool `json:"h2c,omitempty"`
+	// Number of redirects to follow [-1 = will not follow but marks as success] (default 10)
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	Redirects *int32 `json:"redirects,omitempty"`
+	// Maximum number of bytes to capture from response bodies [-1 = no limit] (default -1)
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	MaxBody *int64 `json:"maxBody,omitempty"`
+	// Maximum number of workers (default unlimited)
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	// +kubebuilder:validation:Minimum=1
+	MaxWorkers *int64 `json:"maxWorkers,omitempty"`
+	// Read targets lazily (default false)
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	Lazy *bool `json:"lazy,omitempty"`
+	// Send body with chunked transfer encoding (default false)
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	Chunked *bool `json:"chunked,omitempty"`
+	// Attack name, which is recorded in the results. It can not be used with stages, which name the results by stage.
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	Name string `json:"name,omitempty"`
+	// Local IP address to send requests from (default 0.0.0.0)
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	LocalAddress string `json:"localAddress,omitempty"`
+	// Addresses in the form of "ip[:port]" of DNS servers used instead of the local system DNS
+	// More info: https://github.com/tsenart/vegeta#usage-manual
+	// +optional
+	Resolvers []string `json:"resolvers,omitempty"`
+}
+
+// Header defines a request header
+type Header struct {
+	// Name of the header
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Value of the header
+	Value string `json:"value"`
+}
+
+// RateMode describes how the rate of Attack is applied to the attack pods.
+// Only one of the following rate modes may be specified.
+// +kubebuilder:validation:Enum=perPod;total
+type RateMode string
+
+const (
+	// PerPodRateMode applies the rate to every attack pod
+	PerPodRateMode RateMode = "perPod"
+
+	// TotalRateMode divides the rate across the attack pods
+	TotalRateMode RateMode = "total"
+)
+
+// PodOption defines the vegeta options effectively applied to each attack pod
+type PodOption struct {
+	// Value of -rate, which may be a fraction such as "10/4s". Empty when stages are specified
+	Rate string `json:"rate,omitempty"`
+	// Value of -workers
+	Workers int32 `json:"workers,omitempty"`
+	// Value of -connections
+	Connections int32 `json:"connections,omitempty"`
+}
+
+// Template defines the pod template generated by job
+type Template struct {
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata
+	// +kubebuilder:pruning:PreserveUnknownFields
+	metaV1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              Spec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+}
+
+// Spec defines the additional pod spec generated by job.
+// The fields specified here take precedence over the defaults of the controller, e.g. affinity,
+// while the containers, the volumes, the restart policy and the termination grace period are always
+// determined by the controller, since the attack depends on them.
+type Spec struct {
+	// HostAliases is an optional list of hosts and IPs that will be injected into the pod's hosts
+	// file if specified. This is only valid for non-hostNetwork pods.
+	HostAliases []v1.HostAlias `json:"hostAliases,omitempty" patchStrategy:"merge" patchMergeKey:"ip" protobuf:"bytes,23,rep,name=hostAliases"`
+	// NodeSelector is a selector which must be true for the pod to fit on a node.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations of the attack pods
+	// +optional
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// Affinity of the attack pods.
+	// Each of nodeAffinity, podAffinity and podAntiAffinity replaces the default of the controller when specified,
+	// which prefers spreading the attack pods over nodes by podAntiAffinity.
+	// +optional
+	Affinity *v1.Affinity `json:"affinity,omitempty"`
+	// TopologySpreadConstraints describes how the attack pods ought to spread across topology domains
+	// +optional
+	TopologySpreadConstraints []v1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// PriorityClassName of the attack pods
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// ServiceAccountName of the attack pods
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// SecurityContext holds pod-level security attributes of the attack pods
+	// +optional
+	SecurityContext *v1.PodSecurityContext `json:"securityContext,omitempty"`
+	// ImagePullSecrets to pull vegeta image
+	// +optional
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// DNSPolicy of the attack pods, which is ClusterFirst by default
+	// +kubebuilder:validation:Enum=ClusterFirstWithHostNet;ClusterFirst;Default;None
+	// +optional
+	DNSPolicy v1.DNSPolicy `json:"dnsPolicy,omitempty"`
+	// DNSConfig of the attack pods, which is merged with the configuration generated by dnsPolicy
+	// +optional
+	DNSConfig *v1.PodDNSConfig `json:"dnsConfig,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Active",type="integer",JSONPath=".status.active"
+// +kubebuilder:printcolumn:name="Succeeded",type="integer",JSONPath=".status.succeeded"
+// +kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failed"
+// +kubebuilder:printcolumn:name="Requests",type="integer",JSONPath=".status.report.requests"
+// +kubebuilder:printcolumn:name="Success",type="string",JSONPath=".status.report.success"
+// +kubebuilder:printcolumn:name="Passed",type="string",JSONPath=".status.conditions[?(@.type==\"Passed\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// Attack is the schema for the attacks API
+type Attack struct {
+	metaV1.TypeMeta   `json:",inline"`
+	metaV1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AttackSpec   `json:"spec,omitempty"`
+	Status AttackStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AttackList contains a list of Attack
+type AttackList struct {
+	metaV1.TypeMeta `json:",inline"`
+	metaV1.ListMeta `json:"metadata,omitempty"`
+	Items           []Attack `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Attack{}, &AttackList{})
+}
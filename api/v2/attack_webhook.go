@@ -0,0 +1,637 @@
+package v2
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1Validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func (r *Attack) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-vegeta-kaidotdev-github-io-v2-attack,mutating=false,failurePolicy=fail,groups=vegeta.kaidotdev.github.io,resources=attacks,versions=v2,name=vattack.vegeta.kaidotdev.github.io
+
+var _ webhook.Validator = &Attack{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Attack) ValidateCreate() error {
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+// Updates which keep the spec, such as those of finalizers by the controller, are not validated,
+// so that the attacks stored before the current rules can still be reconciled and deleted.
+func (r *Attack) ValidateUpdate(old runtime.Object) error {
+	if oldAttack, ok := old.(*Attack); ok && (r.DeletionTimestamp != nil || equality.Semantic.DeepEqual(oldAttack.Spec, r.Spec)) {
+		return nil
+	}
+	return r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Attack) ValidateDelete() error {
+	return nil
+}
+
+func (r *Attack) validate() error {
+	errs := validateAttackSpec(&r.Spec, field.NewPath("spec"))
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.NewInvalid(GroupVersion.WithKind("Attack").GroupKind(), r.Name, errs)
+}
+
+// validateAttackSpec checks what the schema of CRD can not, that is the scenario and the consistency of options
+func validateAttackSpec(spec *AttackSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateScenario(spec, path)...)
+	errs = append(errs, validateOption(&spec.Option, path.Child("option"))...)
+	errs = append(errs, validateStages(spec.Stages, &spec.Option, path.Child("stages"))...)
+	if spec.Option.Name != "" && len(spec.Stages) > 0 {
+		errs = append(errs, field.Forbidden(path.Child("option", "name"), "must not be specified with stages, which name the results by stage"))
+	}
+	if spec.Thresholds != nil {
+		errs = append(errs, validateThresholds(spec.Thresholds, path.Child("thresholds"))...)
+	}
+	if spec.TLS != nil {
+		errs = append(errs, validateTLS(spec.TLS, path.Child("tls"))...)
+	}
+	errs = append(errs, validateHeaderVars(spec.Headers, path.Child("headers"))...)
+	errs = append(errs, validateBodies(spec.Bodies, path.Child("bodies"))...)
+	errs = append(errs, validateReports(spec.Reports, path.Child("reports"))...)
+	errs = append(errs, validateAbortConditions(spec.AbortConditions, path.Child("abortConditions"))...)
+	if spec.StartAt != nil && spec.Deadline != nil && !spec.Deadline.After(spec.StartAt.Time) {
+		errs = append(errs, field.Invalid(path.Child("deadline"), spec.Deadline.UTC().Format(time.RFC3339), "must be after startAt"))
+	}
+	if spec.StartBarrier != nil {
+		errs = append(errs, validateStartBarrier(spec.StartBarrier, path.Child("startBarrier"))...)
+	}
+	errs = append(errs, validatePodSpec(&spec.Template.Spec, path.Child("template", "spec"))...)
+	if spec.Placement != nil && spec.Placement.Strategy == CustomPlacementStrategy && spec.Placement.Required {
+		errs = append(errs, field.Forbidden(path.Child("placement", "required"), "must not be specified with Custom strategy, which is determined by the pod template"))
+	}
+	return errs
+}
+
+// validatePodSpec validates the pod spec roughly like kube-apiserver, so that the job is not rejected after Attack is accepted
+func validatePodSpec(spec *Spec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for key, value := range spec.NodeSelector {
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, field.Invalid(path.Child("nodeSelector"), key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			errs = append(errs, field.Invalid(path.Child("nodeSelector").Key(key), value, msg))
+		}
+	}
+	for i, toleration := range spec.Tolerations {
+		errs = append(errs, validateToleration(toleration, path.Child("tolerations").Index(i))...)
+	}
+	for i, constraint := range spec.TopologySpreadConstraints {
+		constraintPath := path.Child("topologySpreadConstraints").Index(i)
+		if constraint.MaxSkew <= 0 {
+			errs = append(errs, field.Invalid(constraintPath.Child("maxSkew"), constraint.MaxSkew, "must be positive"))
+		}
+		if constraint.TopologyKey == "" {
+			errs = append(errs, field.Required(constraintPath.Child("topologyKey"), "must be specified"))
+		}
+		switch constraint.WhenUnsatisfiable {
+		case v1.DoNotSchedule, v1.ScheduleAnyway:
+		default:
+			errs = append(errs, field.NotSupported(constraintPath.Child("whenUnsatisfiable"), constraint.WhenUnsatisfiable, []string{string(v1.DoNotSchedule), string(v1.ScheduleAnyway)}))
+		}
+	}
+	if spec.PriorityClassName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(spec.PriorityClassName) {
+			errs = append(errs, field.Invalid(path.Child("priorityClassName"), spec.PriorityClassName, msg))
+		}
+	}
+	if spec.ServiceAccountName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(spec.ServiceAccountName) {
+			errs = append(errs, field.Invalid(path.Child("serviceAccountName"), spec.ServiceAccountName, msg))
+		}
+	}
+	for i, secret := range spec.ImagePullSecrets {
+		if secret.Name == "" {
+			errs = append(errs, field.Required(path.Child("imagePullSecrets").Index(i).Child("name"), "must be specified"))
+		}
+	}
+	if spec.Affinity != nil {
+		errs = append(errs, validateAffinity(spec.Affinity, path.Child("affinity"))...)
+	}
+	if spec.SecurityContext != nil {
+		errs = append(errs, validatePodSecurityContext(spec.SecurityContext, path.Child("securityContext"))...)
+	}
+	if spec.DNSPolicy == v1.DNSNone && spec.DNSConfig == nil {
+		errs = append(errs, field.Required(path.Child("dnsConfig"), "must be specified when dnsPolicy is None"))
+	}
+	if spec.DNSConfig != nil {
+		errs = append(errs, validateDNSConfig(spec.DNSConfig, spec.DNSPolicy, path.Child("dnsConfig"))...)
+	}
+	return errs
+}
+
+func validateAffinity(affinity *v1.Affinity, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if nodeAffinity := affinity.NodeAffinity; nodeAffinity != nil {
+		nodeAffinityPath := path.Child("nodeAffinity")
+		if required := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+			requiredPath := nodeAffinityPath.Child("requiredDuringSchedulingIgnoredDuringExecution", "nodeSelectorTerms")
+			if len(required.NodeSelectorTerms) == 0 {
+				errs = append(errs, field.Required(requiredPath, "must have at least one node selector term"))
+			}
+			for i, term := range required.NodeSelectorTerms {
+				errs = append(errs, validateNodeSelectorTerm(term, requiredPath.Index(i))...)
+			}
+		}
+		for i, term := range nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			termPath := nodeAffinityPath.Child("preferredDuringSchedulingIgnoredDuringExecution").Index(i)
+			errs = append(errs, validateWeight(term.Weight, termPath.Child("weight"))...)
+			errs = append(errs, validateNodeSelectorTerm(term.Preference, termPath.Child("preference"))...)
+		}
+	}
+	if podAffinity := affinity.PodAffinity; podAffinity != nil {
+		errs = append(errs, validatePodAffinityTerms(
+			podAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			podAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			path.Child("podAffinity"),
+		)...)
+	}
+	if podAntiAffinity := affinity.PodAntiAffinity; podAntiAffinity != nil {
+		errs = append(errs, validatePodAffinityTerms(
+			podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			path.Child("podAntiAffinity"),
+		)...)
+	}
+	return errs
+}
+
+func validateNodeSelectorTerm(term v1.NodeSelectorTerm, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, requirement := range term.MatchExpressions {
+		requirementPath := path.Child("matchExpressions").Index(i)
+		for _, msg := range validation.IsQualifiedName(requirement.Key) {
+			errs = append(errs, field.Invalid(requirementPath.Child("key"), requirement.Key, msg))
+		}
+		switch requirement.Operator {
+		case v1.NodeSelectorOpIn, v1.NodeSelectorOpNotIn:
+			if len(requirement.Values) == 0 {
+				errs = append(errs, field.Required(requirementPath.Child("values"), "must be specified when operator is In or NotIn"))
+			}
+		case v1.NodeSelectorOpExists, v1.NodeSelectorOpDoesNotExist:
+			if len(requirement.Values) > 0 {
+				errs = append(errs, field.Forbidden(requirementPath.Child("values"), "must not be specified when operator is Exists or DoesNotExist"))
+			}
+		case v1.NodeSelectorOpGt, v1.NodeSelectorOpLt:
+			if len(requirement.Values) != 1 {
+				errs = append(errs, field.Required(requirementPath.Child("values"), "must be a single integer when operator is Gt or Lt"))
+			} else if _, err := strconv.ParseInt(requirement.Values[0], 10, 64); err != nil {
+				errs = append(errs, field.Invalid(requirementPath.Child("values").Index(0), requirement.Values[0], "must be an integer when operator is Gt or Lt"))
+			}
+		default:
+			errs = append(errs, field.NotSupported(requirementPath.Child("operator"), requirement.Operator, []string{
+				string(v1.NodeSelectorOpIn),
+				string(v1.NodeSelectorOpNotIn),
+				string(v1.NodeSelectorOpExists),
+				string(v1.NodeSelectorOpDoesNotExist),
+				string(v1.NodeSelectorOpGt),
+				string(v1.NodeSelectorOpLt),
+			}))
+		}
+	}
+	return errs
+}
+
+func validatePodAffinityTerms(required []v1.PodAffinityTerm, preferred []v1.WeightedPodAffinityTerm, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, term := range required {
+		errs = append(errs, validatePodAffinityTerm(term, path.Child("requiredDuringSchedulingIgnoredDuringExecution").Index(i))...)
+	}
+	for i, term := range preferred {
+		termPath := path.Child("preferredDuringSchedulingIgnoredDuringExecution").Index(i)
+		errs = append(errs, validateWeight(term.Weight, termPath.Child("weight"))...)
+		errs = append(errs, validatePodAffinityTerm(term.PodAffinityTerm, termPath.Child("podAffinityTerm"))...)
+	}
+	return errs
+}
+
+func validatePodAffinityTerm(term v1.PodAffinityTerm, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, metaV1Validation.ValidateLabelSelector(term.LabelSelector, path.Child("labelSelector"))...)
+	for i, namespace := range term.Namespaces {
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			errs = append(errs, field.Invalid(path.Child("namespaces").Index(i), namespace, msg))
+		}
+	}
+	if term.TopologyKey == "" {
+		errs = append(errs, field.Required(path.Child("topologyKey"), "must be specified"))
+	} else {
+		for _, msg := range validation.IsQualifiedName(term.TopologyKey) {
+			errs = append(errs, field.Invalid(path.Child("topologyKey"), term.TopologyKey, msg))
+		}
+	}
+	return errs
+}
+
+func validateWeight(weight int32, path *field.Path) field.ErrorList {
+	if weight < 1 || weight > 100 {
+		return field.ErrorList{field.Invalid(path, weight, "must be in the range 1-100")}
+	}
+	return nil
+}
+
+// sysctlPattern is the format of sysctl names accepted by kube-apiserver
+var sysctlPattern = regexp.MustCompile(`^([a-z0-9]([-_a-z0-9]*[a-z0-9])?[./])*[a-z0-9]([-_a-z0-9]*[a-z0-9])?$`)
+
+func validatePodSecurityContext(securityContext *v1.PodSecurityContext, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if securityContext.RunAsUser != nil {
+		for _, msg := range validation.IsValidUserID(*securityContext.RunAsUser) {
+			errs = append(errs, field.Invalid(path.Child("runAsUser"), *securityContext.RunAsUser, msg))
+		}
+		if securityContext.RunAsNonRoot != nil && *securityContext.RunAsNonRoot && *securityContext.RunAsUser == 0 {
+			errs = append(errs, field.Invalid(path.Child("runAsUser"), *securityContext.RunAsUser, "must not be 0 when runAsNonRoot is true"))
+		}
+	}
+	if securityContext.RunAsGroup != nil {
+		for _, msg := range validation.IsValidGroupID(*securityContext.RunAsGroup) {
+			errs = append(errs, field.Invalid(path.Child("runAsGroup"), *securityContext.RunAsGroup, msg))
+		}
+	}
+	if securityContext.FSGroup != nil {
+		for _, msg := range validation.IsValidGroupID(*securityContext.FSGroup) {
+			errs = append(errs, field.Invalid(path.Child("fsGroup"), *securityContext.FSGroup, msg))
+		}
+	}
+	for i, group := range securityContext.SupplementalGroups {
+		for _, msg := range validation.IsValidGroupID(group) {
+			errs = append(errs, field.Invalid(path.Child("supplementalGroups").Index(i), group, msg))
+		}
+	}
+	for i, sysctl := range securityContext.Sysctls {
+		if len(sysctl.Name) > 253 || !sysctlPattern.MatchString(sysctl.Name) {
+			errs = append(errs, field.Invalid(path.Child("sysctls").Index(i).Child("name"), sysctl.Name, "must be a valid sysctl name"))
+		}
+	}
+	return errs
+}
+
+// validateDNSConfig validates dnsConfig with the same limits as kube-apiserver
+func validateDNSConfig(dnsConfig *v1.PodDNSConfig, dnsPolicy v1.DNSPolicy, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(dnsConfig.Nameservers) > 3 {
+		errs = append(errs, field.TooMany(path.Child("nameservers"), len(dnsConfig.Nameservers), 3))
+	}
+	if dnsPolicy == v1.DNSNone && len(dnsConfig.Nameservers) == 0 {
+		errs = append(errs, field.Required(path.Child("nameservers"), "must have at least one nameserver when dnsPolicy is None"))
+	}
+	for i, nameserver := range dnsConfig.Nameservers {
+		if net.ParseIP(nameserver) == nil {
+			errs = append(errs, field.Invalid(path.Child("nameservers").Index(i), nameserver, "must be a valid IP address"))
+		}
+	}
+	if len(dnsConfig.Searches) > 6 {
+		errs = append(errs, field.TooMany(path.Child("searches"), len(dnsConfig.Searches), 6))
+	}
+	for i, search := range dnsConfig.Searches {
+		// Search domains may end with a dot as fully qualified names
+		for _, msg := range validation.IsDNS1123Subdomain(strings.TrimSuffix(search, ".")) {
+			errs = append(errs, field.Invalid(path.Child("searches").Index(i), search, msg))
+		}
+	}
+	for i, option := range dnsConfig.Options {
+		if option.Name == "" {
+			errs = append(errs, field.Required(path.Child("options").Index(i).Child("name"), "must be specified"))
+		}
+	}
+	return errs
+}
+
+func validateToleration(toleration v1.Toleration, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if toleration.Key != "" {
+		for _, msg := range validation.IsQualifiedName(toleration.Key) {
+			errs = append(errs, field.Invalid(path.Child("key"), toleration.Key, msg))
+		}
+	}
+	switch toleration.Operator {
+	case v1.TolerationOpEqual, "":
+	case v1.TolerationOpExists:
+		if toleration.Value != "" {
+			errs = append(errs, field.Invalid(path.Child("value"), toleration.Value, "must be empty when operator is Exists"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(path.Child("operator"), toleration.Operator, []string{string(v1.TolerationOpEqual), string(v1.TolerationOpExists)}))
+	}
+	if toleration.Key == "" && toleration.Operator != v1.TolerationOpExists {
+		errs = append(errs, field.Invalid(path.Child("operator"), toleration.Operator, "must be Exists when key is empty"))
+	}
+	switch toleration.Effect {
+	case "", v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("effect"), toleration.Effect, []string{string(v1.TaintEffectNoSchedule), string(v1.TaintEffectPreferNoSchedule), string(v1.TaintEffectNoExecute)}))
+	}
+	if toleration.TolerationSeconds != nil && toleration.Effect != v1.TaintEffectNoExecute {
+		errs = append(errs, field.Invalid(path.Child("tolerationSeconds"), *toleration.TolerationSeconds, "must be specified only when effect is NoExecute"))
+	}
+	return errs
+}
+
+func validateScenario(spec *AttackSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	source := spec.ScenarioFrom
+	switch {
+	case source != nil && spec.Scenario != "":
+		errs = append(errs, field.Forbidden(path.Child("scenarioFrom"), "must not be specified with scenario"))
+	case source != nil:
+		if (source.ConfigMapKeyRef == nil) == (source.SecretKeyRef == nil) {
+			errs = append(errs, field.Invalid(path.Child("scenarioFrom"), "", "exactly one of configMapKeyRef and secretKeyRef must be specified"))
+		}
+		// The referenced scenario can not be validated here since it may be created or changed later
+	case spec.Scenario == "":
+		errs = append(errs, field.Required(path.Child("scenario"), "either scenario or scenarioFrom must be specified"))
+	default:
+		bodies := map[string]bool{}
+		for _, body := range spec.Bodies {
+			bodies[body.Name] = true
+		}
+		for _, err := range parseTargets(spec.Scenario, spec.Option.Format, bodies) {
+			if err.text == "" {
+				errs = append(errs, field.Required(path.Child("scenario"), err.Error()))
+				continue
+			}
+			errs = append(errs, field.Invalid(path.Child("scenario"), err.text, err.Error()))
+		}
+	}
+	return errs
+}
+
+func validateOption(option *VegetaOption, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if option.Duration != nil && option.Duration.Duration < 0 {
+		errs = append(errs, field.Invalid(path.Child("duration"), option.Duration.Duration.String(), "must not be negative"))
+	}
+	if option.Timeout != nil && option.Timeout.Duration < 0 {
+		errs = append(errs, field.Invalid(path.Child("timeout"), option.Timeout.Duration.String(), "must not be negative"))
+	}
+	if option.Rate != nil {
+		errs = append(errs, validateRate(*option.Rate, option.MaxWorkers != nil, path.Child("rate"))...)
+	}
+	if option.Keepalive != nil && !*option.Keepalive && option.Connections != nil {
+		errs = append(errs, field.Forbidden(path.Child("connections"), "idle connections are not kept when keepalive is false"))
+	}
+	if option.HTTP2 != nil && !*option.HTTP2 && option.H2C != nil && *option.H2C {
+		errs = append(errs, field.Forbidden(path.Child("h2c"), "must not be enabled when http2 is disabled"))
+	}
+	if option.Redirects != nil && *option.Redirects < -1 {
+		errs = append(errs, field.Invalid(path.Child("redirects"), *option.Redirects, "must be greater than or equal to -1"))
+	}
+	if option.MaxBody != nil && *option.MaxBody < -1 {
+		errs = append(errs, field.Invalid(path.Child("maxBody"), *option.MaxBody, "must be greater than or equal to -1"))
+	}
+	for i, header := range option.Headers {
+		headerPath := path.Child("headers").Index(i)
+		if !headerNamePattern.MatchString(header.Name) {
+			errs = append(errs, field.Invalid(headerPath.Child("name"), header.Name, "must be a valid HTTP header name"))
+		}
+		if strings.ContainsAny(header.Value, "\r\n") {
+			errs = append(errs, field.Invalid(headerPath.Child("value"), header.Value, "must not contain line breaks"))
+		}
+	}
+	if option.LocalAddress != "" && net.ParseIP(option.LocalAddress) == nil {
+		errs = append(errs, field.Invalid(path.Child("localAddress"), option.LocalAddress, "must be an IP address"))
+	}
+	for i, resolver := range option.Resolvers {
+		if !isResolverAddress(resolver) {
+			errs = append(errs, field.Invalid(path.Child("resolvers").Index(i), resolver, `must be an IP address with optional port, e.g. "8.8.8.8:53"`))
+		}
+	}
+	return errs
+}
+
+// headerNamePattern matches the token of RFC 7230, which vegeta splits from the value at the first colon
+var headerNamePattern = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+func isResolverAddress(address string) bool {
+	if net.ParseIP(address) != nil {
+		return true
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) == nil {
+		return false
+	}
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n < 65536
+}
+
+// validateRate validates the rate, where zero means unlimited, which is allowed only when it is bounded by maxWorkers
+func validateRate(rate intstr.IntOrString, unlimited bool, path *field.Path) field.ErrorList {
+	freq, _, err := ParseRate(rate)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, rate.String(), err.Error())}
+	}
+	if freq == 0 && !unlimited {
+		return field.ErrorList{field.Invalid(path, rate.String(), "must be positive unless maxWorkers is specified")}
+	}
+	return nil
+}
+
+// isUnlimitedRate returns whether the rate is zero, which sends requests as fast as maxWorkers allow
+func isUnlimitedRate(rate *intstr.IntOrString) bool {
+	if rate == nil {
+		return false
+	}
+	freq, _, err := ParseRate(*rate)
+	return err == nil && freq == 0
+}
+
+func validateStages(stages []Stage, option *VegetaOption, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	unlimited := isUnlimitedRate(option.Rate)
+	for i, stage := range stages {
+		stagePath := path.Index(i)
+		if stage.Rate != nil {
+			errs = append(errs, validateRate(*stage.Rate, option.MaxWorkers != nil, stagePath.Child("rate"))...)
+		}
+		if stage.TargetRate != nil {
+			// A ramp needs the finite rates at both ends to interpolate
+			errs = append(errs, validateRate(*stage.TargetRate, false, stagePath.Child("targetRate"))...)
+			if unlimited {
+				errs = append(errs, field.Forbidden(stagePath.Child("targetRate"), "must not follow the unlimited rate"))
+			}
+		}
+		switch {
+		case stage.Rate != nil:
+			unlimited = isUnlimitedRate(stage.Rate)
+		case stage.TargetRate != nil:
+			unlimited = false
+		}
+		if stage.Rate != nil && stage.TargetRate != nil {
+			errs = append(errs, field.Forbidden(stagePath.Child("targetRate"), "must not be specified with rate"))
+		}
+		if stage.Duration.Duration < 0 {
+			errs = append(errs, field.Invalid(stagePath.Child("duration"), stage.Duration.Duration.String(), "must not be negative"))
+		}
+		// Each step of the ramp lasts a second at least, shorter ones of which can not approximate the ramp
+		if minDuration := RampSteps * time.Second; stage.TargetRate != nil && stage.Duration.Duration > 0 && stage.Duration.Duration < minDuration {
+			errs = append(errs, field.Invalid(stagePath.Child("duration"), stage.Duration.Duration.String(), fmt.Sprintf("must be at least %s to change the rate to targetRate in %d steps", minDuration, RampSteps)))
+		}
+		if stage.Duration.Duration != 0 {
+			continue
+		}
+		// Zero duration means forever in vegeta
+		if stage.TargetRate != nil {
+			errs = append(errs, field.Invalid(stagePath.Child("duration"), stage.Duration.Duration.String(), "must be positive to change the rate to targetRate"))
+		}
+		if i != len(stages)-1 {
+			errs = append(errs, field.Invalid(stagePath.Child("duration"), stage.Duration.Duration.String(), "only the last stage can run forever"))
+		}
+	}
+	return errs
+}
+
+func validateHeaderVars(headers []HeaderVar, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, header := range headers {
+		headerPath := path.Index(i)
+		if !headerNamePattern.MatchString(header.Name) {
+			errs = append(errs, field.Invalid(headerPath.Child("name"), header.Name, "must be a valid HTTP header name"))
+		}
+		switch {
+		case header.ValueFrom != nil && header.Value != "":
+			// The value is not shown since it may be a credential put by mistake
+			errs = append(errs, field.Forbidden(headerPath.Child("valueFrom"), "must not be specified with value"))
+		case header.ValueFrom != nil && header.ValueFrom.SecretKeyRef == nil:
+			errs = append(errs, field.Required(headerPath.Child("valueFrom", "secretKeyRef"), "must be specified"))
+		case header.ValueFrom != nil && (header.ValueFrom.SecretKeyRef.Name == "" || header.ValueFrom.SecretKeyRef.Key == ""):
+			errs = append(errs, field.Required(headerPath.Child("valueFrom", "secretKeyRef"), "name and key must be specified"))
+		case strings.ContainsAny(header.Value, "\r\n"):
+			errs = append(errs, field.Invalid(headerPath.Child("value"), "", "must not contain line breaks"))
+		}
+	}
+	return errs
+}
+
+func validateBodies(bodies []Body, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	names := map[string]bool{}
+	for i, body := range bodies {
+		bodyPath := path.Index(i)
+		if names[body.Name] {
+			errs = append(errs, field.Duplicate(bodyPath.Child("name"), body.Name))
+		}
+		names[body.Name] = true
+
+		specified := 0
+		for _, ok := range []bool{body.Data != "", len(body.BinaryData) > 0, body.ValueFrom != nil} {
+			if ok {
+				specified++
+			}
+		}
+		if specified > 1 {
+			errs = append(errs, field.Invalid(bodyPath, "", "at most one of data, binaryData and valueFrom may be specified"))
+		}
+		if source := body.ValueFrom; source != nil && (source.ConfigMapKeyRef == nil) == (source.SecretKeyRef == nil) {
+			errs = append(errs, field.Invalid(bodyPath.Child("valueFrom"), "", "exactly one of configMapKeyRef and secretKeyRef must be specified"))
+		}
+	}
+	return errs
+}
+
+func validateReports(reports []ReportOutput, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	keys := map[string]bool{}
+	for i, report := range reports {
+		reportPath := path.Index(i)
+		if key := report.Key(); keys[key] {
+			errs = append(errs, field.Duplicate(reportPath.Child("name"), key))
+		} else {
+			keys[key] = true
+		}
+
+		if report.Type != HistReportType && len(report.Buckets) > 0 {
+			errs = append(errs, field.Forbidden(reportPath.Child("buckets"), "may only be specified for hist"))
+		}
+		if report.Type == HistReportType && len(report.Buckets) == 0 {
+			errs = append(errs, field.Required(reportPath.Child("buckets"), "must be specified for hist"))
+		}
+		for j, bucket := range report.Buckets {
+			if bucket.Duration < 0 {
+				errs = append(errs, field.Invalid(reportPath.Child("buckets").Index(j), bucket.Duration.String(), "must not be negative"))
+			} else if j > 0 && bucket.Duration <= report.Buckets[j-1].Duration {
+				errs = append(errs, field.Invalid(reportPath.Child("buckets").Index(j), bucket.Duration.String(), "must be greater than the previous bucket"))
+			}
+		}
+		if report.Type != PlotReportType && (report.Title != "" || report.Threshold != nil) {
+			errs = append(errs, field.Forbidden(reportPath, "title and threshold may only be specified for plot"))
+		}
+	}
+	return errs
+}
+
+func validateAbortConditions(conditions []AbortCondition, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, condition := range conditions {
+		conditionPath := path.Index(i)
+		if condition.MaxErrorRatio == "" && condition.MaxLatencyP99 == nil && condition.MaxLatencyP95 == nil && condition.MaxLatencyMean == nil {
+			errs = append(errs, field.Required(conditionPath, "at least one of maxErrorRatio, maxLatencyP99, maxLatencyP95 and maxLatencyMean must be specified"))
+		}
+		if condition.Window != nil && condition.Window.Duration <= 0 {
+			errs = append(errs, field.Invalid(conditionPath.Child("window"), condition.Window.Duration.String(), "must be positive"))
+		}
+		if condition.For != nil && condition.For.Duration < 0 {
+			errs = append(errs, field.Invalid(conditionPath.Child("for"), condition.For.Duration.String(), "must not be negative"))
+		}
+	}
+	return errs
+}
+
+func validateStartBarrier(barrier *StartBarrier, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if barrier.Timeout != nil && barrier.Timeout.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("timeout"), barrier.Timeout.Duration.String(), "must be positive"))
+	}
+	if barrier.Delay != nil && barrier.Delay.Duration < 0 {
+		errs = append(errs, field.Invalid(path.Child("delay"), barrier.Delay.Duration.String(), "must not be negative"))
+	}
+	return errs
+}
+
+func validateTLS(tls *TLS, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if tls.ClientCertSecretRef != nil && tls.ClientCertSecretRef.Name == "" {
+		errs = append(errs, field.Required(path.Child("clientCertSecretRef", "name"), "must be specified"))
+	}
+	if source := tls.RootCAs; source != nil && (source.ConfigMapKeyRef == nil) == (source.SecretKeyRef == nil) {
+		errs = append(errs, field.Invalid(path.Child("rootCAs"), "", "exactly one of configMapKeyRef and secretKeyRef must be specified"))
+	}
+	return errs
+}
+
+func validateThresholds(thresholds *Thresholds, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, code := range thresholds.AllowedStatusCodes {
+		if code < 0 || code > 599 {
+			errs = append(errs, field.Invalid(path.Child("allowedStatusCodes").Index(i), code, "must be between 0 and 599"))
+		}
+	}
+	return errs
+}
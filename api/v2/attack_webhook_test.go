@@ -0,0 +1,198 @@
+package v2
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateAttackSpec(t *testing.T) {
+	rateOf := func(value intstr.IntOrString) *intstr.IntOrString {
+		return &value
+	}
+	boolPtr := func(b bool) *bool {
+		return &b
+	}
+	int32Ptr := func(n int32) *int32 {
+		return &n
+	}
+	int64Ptr := func(n int64) *int64 {
+		return &n
+	}
+	startAt := metaV1.NewTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name string
+		spec AttackSpec
+		// want is the fields of the errors in order
+		want []string
+	}{
+		{
+			name: "valid",
+			spec: AttackSpec{
+				Scenario: "GET http://example.com/",
+				Option:   VegetaOption{Rate: rateOf(intstr.FromString("10/1m"))},
+				Stages: []Stage{
+					{Duration: metaV1.Duration{Duration: time.Minute}, TargetRate: rateOf(intstr.FromInt(100))},
+					{Duration: metaV1.Duration{Duration: 0}},
+				},
+			},
+		},
+		{
+			name: "no scenario",
+			spec: AttackSpec{},
+			want: []string{"spec.scenario"},
+		},
+		{
+			name: "both scenario and scenarioFrom",
+			spec: AttackSpec{
+				Scenario:     "GET http://example.com/",
+				ScenarioFrom: &ScenarioSource{},
+			},
+			want: []string{"spec.scenarioFrom"},
+		},
+		{
+			name: "invalid scenario",
+			spec: AttackSpec{Scenario: "GET example.com"},
+			want: []string{"spec.scenario"},
+		},
+		{
+			name: "inconsistent options",
+			spec: AttackSpec{
+				Scenario: "GET http://example.com/",
+				Option: VegetaOption{
+					Rate:        rateOf(intstr.FromString("0")),
+					Keepalive:   boolPtr(false),
+					Connections: int32Ptr(10),
+					HTTP2:       boolPtr(false),
+					H2C:         boolPtr(true),
+				},
+			},
+			want: []string{"spec.option.rate", "spec.option.connections", "spec.option.h2c"},
+		},
+		{
+			name: "unlimited rate with maxWorkers",
+			spec: AttackSpec{
+				Scenario: "GET http://example.com/",
+				Option: VegetaOption{
+					Rate:       rateOf(intstr.FromString("0")),
+					MaxWorkers: int64Ptr(10),
+				},
+				Stages: []Stage{
+					{Duration: metaV1.Duration{Duration: time.Minute}},
+					{Duration: metaV1.Duration{Duration: time.Minute}, TargetRate: rateOf(intstr.FromInt(0))},
+					{Duration: metaV1.Duration{Duration: time.Minute}, Rate: rateOf(intstr.FromInt(0))},
+				},
+			},
+			want: []string{"spec.stages[1].targetRate", "spec.stages[1].targetRate"},
+		},
+		{
+			name: "invalid stages",
+			spec: AttackSpec{
+				Scenario: "GET http://example.com/",
+				Option:   VegetaOption{Name: "attack"},
+				Stages: []Stage{
+					{Duration: metaV1.Duration{Duration: 0}, Rate: rateOf(intstr.FromInt(10)), TargetRate: rateOf(intstr.FromString("10/0s"))},
+					{Duration: metaV1.Duration{Duration: -time.Second}},
+				},
+			},
+			want: []string{
+				"spec.stages[0].targetRate",
+				"spec.stages[0].targetRate",
+				"spec.stages[0].duration",
+				"spec.stages[0].duration",
+				"spec.stages[1].duration",
+				"spec.option.name",
+			},
+		},
+		{
+			name: "too short ramp",
+			spec: AttackSpec{
+				Scenario: "GET http://example.com/",
+				Stages: []Stage{
+					{Duration: metaV1.Duration{Duration: 10 * time.Second}, TargetRate: rateOf(intstr.FromInt(100))},
+					{Duration: metaV1.Duration{Duration: 9 * time.Second}, TargetRate: rateOf(intstr.FromInt(10))},
+					{Duration: metaV1.Duration{Duration: 5 * time.Second}, Rate: rateOf(intstr.FromInt(10))},
+				},
+			},
+			want: []string{"spec.stages[1].duration"},
+		},
+		{
+			name: "deadline before startAt",
+			spec: AttackSpec{
+				Scenario: "GET http://example.com/",
+				StartAt:  &startAt,
+				Deadline: &startAt,
+			},
+			want: []string{"spec.deadline"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range validateAttackSpec(&tt.spec, field.NewPath("spec")) {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateAttackSpec() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttackValidateUpdate(t *testing.T) {
+	// The attack stored before the current rules, whose scenario is invalid
+	old := &Attack{
+		ObjectMeta: metaV1.ObjectMeta{Name: "attack"},
+		Spec:       AttackSpec{Scenario: "GET example.com"},
+	}
+	now := metaV1.Now()
+
+	tests := []struct {
+		name    string
+		update  func(attack *Attack)
+		wantErr bool
+	}{
+		{
+			name: "finalizer added",
+			update: func(attack *Attack) {
+				attack.Finalizers = append(attack.Finalizers, "vegeta.kaidotdev.github.io/results")
+			},
+		},
+		{
+			name: "finalizer removed from the deleted attack",
+			update: func(attack *Attack) {
+				attack.DeletionTimestamp = &now
+				attack.Spec.Parallelism = 2
+			},
+		},
+		{
+			name: "spec changed",
+			update: func(attack *Attack) {
+				attack.Spec.Parallelism = 2
+			},
+			wantErr: true,
+		},
+		{
+			name: "spec fixed",
+			update: func(attack *Attack) {
+				attack.Spec.Scenario = "GET http://example.com/"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attack := old.DeepCopy()
+			tt.update(attack)
+			if err := attack.ValidateUpdate(old); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package v2
+
+import (
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition contains details for one aspect of the current state of a resource.
+// It has the same shape as metav1.Condition, which is not available in the apimachinery version we depend on.
+type Condition struct {
+	// Type of condition in CamelCase
+	Type string `json:"type"`
+	// Status of the condition, one of True, False, Unknown
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	Status metaV1.ConditionStatus `json:"status"`
+	// ObservedGeneration represents the .metadata.generation that the condition was set based upon
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Last time the condition transitioned from one status to another
+	LastTransitionTime metaV1.Time `json:"lastTransitionTime"`
+	// Reason contains a programmatic identifier indicating the reason for the condition's last transition
+	Reason string `json:"reason"`
+	// Message is a human readable message indicating details about the transition
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// FindCondition returns the condition of the given type, or nil if it is not present
+func FindCondition(conditions []Condition, conditionType string) *Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds or updates the condition of the same type.
+// LastTransitionTime is only bumped when the status actually changes.
+func SetCondition(conditions *[]Condition, newCondition Condition) {
+	existing := FindCondition(*conditions, newCondition.Type)
+	if existing == nil {
+		if newCondition.LastTransitionTime.IsZero() {
+			newCondition.LastTransitionTime = metaV1.Now()
+		}
+		*conditions = append(*conditions, newCondition)
+		return
+	}
+
+	if existing.Status != newCondition.Status {
+		existing.Status = newCondition.Status
+		if newCondition.LastTransitionTime.IsZero() {
+			existing.LastTransitionTime = metaV1.Now()
+		} else {
+			existing.LastTransitionTime = newCondition.LastTransitionTime
+		}
+	}
+	existing.Reason = newCondition.Reason
+	existing.Message = newCondition.Message
+	existing.ObservedGeneration = newCondition.ObservedGeneration
+}
+
+// IsConditionTrue returns true if the condition of the given type is present and True
+func IsConditionTrue(conditions []Condition, conditionType string) bool {
+	condition := FindCondition(conditions, conditionType)
+	return condition != nil && condition.Status == metaV1.ConditionTrue
+}
@@ -0,0 +1,107 @@
+package v2
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CronAttackSpec defines the desired state of CronAttack
+type CronAttackSpec struct {
+	// The schedule in Cron format, see https://en.wikipedia.org/wiki/Cron.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+	// Optional deadline in seconds for starting the attack if it misses scheduled
+	// time for any reason. Missed attack executions are skipped.
+	// Unlike CronJob, which starts nothing when more than 100 start times were missed, the latest missed run
+	// is still started in that case, with a TooManyMissedTimes warning event.
+	// +kubebuilder:validation:Minimum=0
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// Specifies how to treat concurrent executions of an Attack.
+	// Valid values are:
+	// - "Allow" (default): allows CronAttacks to run concurrently;
+	// - "Forbid": forbids concurrent runs, skipping next run if previous run hasn't finished yet;
+	// - "Replace": cancels currently running attack and replaces it with a new one, which is created after the old
+	//   one has been deleted
+	// +kubebuilder:default=Allow
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	// This flag tells the controller to suspend subsequent executions, it does
+	// not apply to already started executions. Defaults to false.
+	Suspend *bool `json:"suspend,omitempty"`
+	// Specifies the attack that will be created when executing a CronAttack.
+	AttackTemplate AttackTemplateSpec `json:"attackTemplate"`
+	// The number of successful finished attacks to retain.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=3
+	SuccessfulAttacksHistoryLimit *int32 `json:"successfulAttacksHistoryLimit,omitempty"`
+	// The number of failed finished attacks to retain.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	FailedAttacksHistoryLimit *int32 `json:"failedAttacksHistoryLimit,omitempty"`
+}
+
+// ConcurrencyPolicy describes how the attack will be handled.
+// Only one of the following concurrent policies may be specified.
+// If none of the following policies is specified, the default one
+// is AllowConcurrent.
+// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent allows CronAttacks to run concurrently.
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+
+	// ForbidConcurrent forbids concurrent runs, skipping next run if previous
+	// hasn't finished yet.
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+
+	// ReplaceConcurrent cancels currently running attack and replaces it with a new one.
+	// The new attack is created after the old one has been deleted, so that they never run at the same time.
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
+// AttackTemplateSpec describes the data an Attack should have when created from a template
+type AttackTemplateSpec struct {
+	// Standard object's metadata of the attacks created from this template.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	metaV1.ObjectMeta `json:"metadata,omitempty"`
+	// Specification of the desired behavior of the attack.
+	Spec AttackSpec `json:"spec"`
+}
+
+// CronAttackStatus defines the observed state of CronAttack
+type CronAttackStatus struct {
+	// A list of pointers to currently running attacks.
+	Active []v1.ObjectReference `json:"active,omitempty"`
+	// Information when was the last time the attack was successfully scheduled.
+	LastScheduleTime *metaV1.Time `json:"lastScheduleTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule"
+// +kubebuilder:printcolumn:name="Suspend",type="boolean",JSONPath=".spec.suspend"
+// +kubebuilder:printcolumn:name="Last Schedule",type="date",JSONPath=".status.lastScheduleTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// CronAttack is the schema for the cronattacks API
+type CronAttack struct {
+	metaV1.TypeMeta   `json:",inline"`
+	metaV1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CronAttackSpec   `json:"spec,omitempty"`
+	Status CronAttackStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CronAttackList contains a list of CronAttack
+type CronAttackList struct {
+	metaV1.TypeMeta `json:",inline"`
+	metaV1.ListMeta `json:"metadata,omitempty"`
+	Items           []CronAttack `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CronAttack{}, &CronAttackList{})
+}
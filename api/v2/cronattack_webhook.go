@@ -0,0 +1,48 @@
+package v2
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func (r *CronAttack) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-vegeta-kaidotdev-github-io-v2-cronattack,mutating=false,failurePolicy=fail,groups=vegeta.kaidotdev.github.io,resources=cronattacks,versions=v2,name=vcronattack.vegeta.kaidotdev.github.io
+
+var _ webhook.Validator = &CronAttack{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *CronAttack) ValidateCreate() error {
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+// Updates which keep the spec are not validated in the same way as Attack.
+func (r *CronAttack) ValidateUpdate(old runtime.Object) error {
+	if oldCronAttack, ok := old.(*CronAttack); ok && (r.DeletionTimestamp != nil || equality.Semantic.DeepEqual(oldCronAttack.Spec, r.Spec)) {
+		return nil
+	}
+	return r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *CronAttack) ValidateDelete() error {
+	return nil
+}
+
+// validate checks the template in the same way as Attack, so that invalid attacks are not created on schedule
+func (r *CronAttack) validate() error {
+	errs := validateAttackSpec(&r.Spec.AttackTemplate.Spec, field.NewPath("spec", "attackTemplate", "spec"))
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.NewInvalid(GroupVersion.WithKind("CronAttack").GroupKind(), r.Name, errs)
+}
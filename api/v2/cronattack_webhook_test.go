@@ -0,0 +1,57 @@
+package v2
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestCronAttackValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		scenario string
+		want     []string
+	}{
+		{
+			name:     "valid template",
+			scenario: "GET http://example.com/",
+		},
+		{
+			name:     "invalid template",
+			scenario: "GET ftp://example.com/",
+			want:     []string{"spec.attackTemplate.spec.scenario"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cronAttack := &CronAttack{
+				Spec: CronAttackSpec{
+					Schedule: "* * * * *",
+					AttackTemplate: AttackTemplateSpec{
+						Spec: AttackSpec{Scenario: tt.scenario},
+					},
+				},
+			}
+			err := cronAttack.ValidateCreate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("ValidateCreate() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.IsInvalid(err) {
+				t.Fatalf("ValidateCreate() error = %v, want Invalid", err)
+			}
+			causes := err.(*errors.StatusError).ErrStatus.Details.Causes
+			if len(causes) != len(tt.want) {
+				t.Fatalf("ValidateCreate() causes = %+v, want %q", causes, tt.want)
+			}
+			for i, cause := range causes {
+				if cause.Field != tt.want[i] {
+					t.Errorf("ValidateCreate() causes[%d].Field = %s, want %s", i, cause.Field, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package v2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ParseRate parses the rate in the same syntax as -rate of vegeta, returning the number of requests per the time unit.
+// An integer is the number of requests per second.
+// More info: https://github.com/tsenart/vegeta#-rate
+func ParseRate(rate intstr.IntOrString) (int64, time.Duration, error) {
+	if rate.Type == intstr.Int {
+		if rate.IntVal < 0 {
+			return 0, 0, fmt.Errorf("rate must not be negative")
+		}
+		return int64(rate.IntVal), time.Second, nil
+	}
+
+	tokens := strings.SplitN(rate.StrVal, "/", 2)
+	freq, err := strconv.ParseInt(tokens[0], 10, 64)
+	if err != nil || freq < 0 {
+		return 0, 0, fmt.Errorf("invalid number of requests %q", tokens[0])
+	}
+	if len(tokens) == 1 {
+		return freq, time.Second, nil
+	}
+
+	unit := tokens[1]
+	// Same as vegeta, the time unit without number means one unit
+	if unit != "" && (unit[0] < '0' || unit[0] > '9') {
+		unit = "1" + unit
+	}
+	per, err := time.ParseDuration(unit)
+	if err != nil || per <= 0 {
+		return 0, 0, fmt.Errorf("invalid time unit %q", tokens[1])
+	}
+	return freq, per, nil
+}
@@ -0,0 +1,167 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// httpMethodPattern is the same as the one vegeta uses to find the beginning of a target in http format
+var httpMethodPattern = regexp.MustCompile(`^[A-Z]+\s`)
+
+// targetError is an error of the target at the line of the scenario
+// +kubebuilder:object:generate=false
+type targetError struct {
+	line   int
+	text   string
+	reason string
+}
+
+func (e *targetError) Error() string {
+	if e.line == 0 {
+		return e.reason
+	}
+	return fmt.Sprintf("line %d: %s", e.line, e.reason)
+}
+
+// jsonTarget is a target in json format
+// More info: https://github.com/tsenart/vegeta#json-format
+// +kubebuilder:object:generate=false
+type jsonTarget struct {
+	Method string              `json:"method"`
+	URL    string              `json:"url"`
+	Body   []byte              `json:"body"`
+	Header map[string][]string `json:"header"`
+}
+
+// parseTargets parses all targets of the scenario in the format, returning the errors of invalid targets.
+// bodies are the names of the bodies which targets can reference.
+func parseTargets(scenario string, format string, bodies map[string]bool) []*targetError {
+	switch format {
+	case "json":
+		return parseJSONTargets(scenario)
+	default:
+		return parseHTTPTargets(scenario, bodies)
+	}
+}
+
+// parseHTTPTargets parses targets in the same grammar as vegeta
+// More info: https://github.com/tsenart/vegeta#http-format
+func parseHTTPTargets(scenario string, bodies map[string]bool) []*targetError {
+	var errs []*targetError
+	inTarget := false
+	hasBody := false
+	broken := false
+	count := 0
+
+	for i, line := range strings.Split(scenario, "\n") {
+		n := i + 1
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			inTarget = false
+			broken = false
+		case strings.HasPrefix(line, "#"):
+			// Comments are ignored by vegeta
+		case httpMethodPattern.MatchString(line):
+			inTarget = true
+			hasBody = false
+			broken = false
+			count++
+			// vegeta splits the method and URL only at a space, so that the target separated by other whitespaces is broken
+			tokens := strings.SplitN(line, " ", 2)
+			if len(tokens) < 2 {
+				errs = append(errs, &targetError{line: n, text: line, reason: "HTTP method and URL must be separated by a space"})
+				broken = true
+				continue
+			}
+			if err := validateTargetURL(strings.TrimSpace(tokens[1])); err != nil {
+				errs = append(errs, &targetError{line: n, text: line, reason: err.Error()})
+			}
+		case !inTarget:
+			errs = append(errs, &targetError{line: n, text: line, reason: "target must start with an uppercase HTTP method followed by URL"})
+			inTarget = true
+			broken = true
+		case broken:
+			// Skip the following lines of the broken target not to report it repeatedly
+		case hasBody:
+			errs = append(errs, &targetError{line: n, text: line, reason: "body must be the last line of the target"})
+		case strings.HasPrefix(line, "@"):
+			hasBody = true
+			if err := validateBodyReference(strings.TrimSpace(strings.TrimPrefix(line, "@")), bodies); err != nil {
+				errs = append(errs, &targetError{line: n, text: line, reason: err.Error()})
+			}
+		default:
+			tokens := strings.SplitN(line, ":", 2)
+			if len(tokens) < 2 || strings.TrimSpace(tokens[0]) == "" {
+				errs = append(errs, &targetError{line: n, text: line, reason: "header must be in the form of \"Key: Value\""})
+			}
+		}
+	}
+	if count == 0 && len(errs) == 0 {
+		errs = append(errs, &targetError{reason: "no targets are found"})
+	}
+	return errs
+}
+
+// parseJSONTargets parses newline delimited targets in json format
+// More info: https://github.com/tsenart/vegeta#json-format
+func parseJSONTargets(scenario string) []*targetError {
+	var errs []*targetError
+	count := 0
+
+	for i, line := range strings.Split(scenario, "\n") {
+		n := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		count++
+
+		var target jsonTarget
+		if err := json.Unmarshal([]byte(line), &target); err != nil {
+			errs = append(errs, &targetError{line: n, text: line, reason: fmt.Sprintf("invalid JSON target: %s", err)})
+			continue
+		}
+		if target.Method == "" {
+			errs = append(errs, &targetError{line: n, text: line, reason: "method is required"})
+		}
+		if err := validateTargetURL(target.URL); err != nil {
+			errs = append(errs, &targetError{line: n, text: line, reason: err.Error()})
+		}
+	}
+	if count == 0 && len(errs) == 0 {
+		errs = append(errs, &targetError{reason: "no targets are found"})
+	}
+	return errs
+}
+
+func validateTargetURL(raw string) error {
+	u, err := url.ParseRequestURI(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %s", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid URL %q: scheme must be http or https", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid URL %q: host is required", raw)
+	}
+	return nil
+}
+
+func validateBodyReference(path string, bodies map[string]bool) error {
+	if path == "" {
+		return fmt.Errorf("body file is required after \"@\"")
+	}
+	if strings.ContainsAny(path, " \t") {
+		return fmt.Errorf("body file %q must not contain whitespaces", path)
+	}
+	// Only the bodies are available in the attack pods
+	if name := strings.TrimPrefix(path, BodyMountPath+"/"); name == path || !bodies[name] {
+		return fmt.Errorf("body file %q must be one of bodies in the form of \"%s/<name>\"", path, BodyMountPath)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package v2
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	bodies := map[string]bool{"payload.json": true}
+
+	tests := []struct {
+		name     string
+		scenario string
+		format   string
+		want     []string
+	}{
+		{
+			name:     "http targets",
+			scenario: "# comment\nGET http://example.com/\n\nPOST https://example.com/users\nContent-Type: application/json\n@/var/lib/vegeta-bodies/payload.json\n",
+		},
+		{
+			name:     "no http targets",
+			scenario: "# comment\n",
+			want:     []string{"no targets are found"},
+		},
+		{
+			name:     "invalid URLs",
+			scenario: "GET /path\n\nGET ftp://example.com/\n\nGET http:///path\n",
+			want: []string{
+				`line 1: invalid URL "/path": scheme must be http or https`,
+				`line 3: invalid URL "ftp://example.com/": scheme must be http or https`,
+				`line 5: invalid URL "http:///path": host is required`,
+			},
+		},
+		{
+			name:     "method and URL separated by a tab",
+			scenario: "GET\thttp://example.com/\nHost: example.com\n\nGET http://example.com/\n",
+			want:     []string{"line 1: HTTP method and URL must be separated by a space"},
+		},
+		{
+			name:     "broken target reported once",
+			scenario: "get http://example.com/\nHost: example.com\n\nGET http://example.com/\n",
+			want:     []string{"line 1: target must start with an uppercase HTTP method followed by URL"},
+		},
+		{
+			name:     "invalid headers and bodies",
+			scenario: "POST http://example.com/\nInvalid\n@/etc/passwd\nHost: example.com\n\nPOST http://example.com/\n@/var/lib/vegeta-bodies/missing.json\n",
+			want: []string{
+				`line 2: header must be in the form of "Key: Value"`,
+				`line 3: body file "/etc/passwd" must be one of bodies in the form of "/var/lib/vegeta-bodies/<name>"`,
+				"line 4: body must be the last line of the target",
+				`line 7: body file "/var/lib/vegeta-bodies/missing.json" must be one of bodies in the form of "/var/lib/vegeta-bodies/<name>"`,
+			},
+		},
+		{
+			name:     "json targets",
+			scenario: "{\"method\": \"GET\", \"url\": \"http://example.com/\"}\n\n{\"method\": \"POST\", \"url\": \"http://example.com/\", \"body\": \"Ym9keQ==\"}\n",
+			format:   "json",
+		},
+		{
+			name:     "invalid json targets",
+			scenario: "{\"url\": \"http://example.com/\"}\n{\"method\": \"GET\", \"url\": \"/path\"}\nGET http://example.com/\n",
+			format:   "json",
+			want: []string{
+				"line 1: method is required",
+				`line 2: invalid URL "/path": scheme must be http or https`,
+				"line 3: invalid JSON target: invalid character 'G' looking for beginning of value",
+			},
+		},
+		{
+			name:   "no json targets",
+			format: "json",
+			want:   []string{"no targets are found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range parseTargets(tt.scenario, tt.format, bodies) {
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTargets() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	"github.com/go-logr/logr"
+	batchV1 "k8s.io/api/batch/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	abortedAnnotation = "vegeta.kaidotdev.github.io/aborted"
+	// abortEvaluationInterval is how often abort conditions are evaluated while the attack is running
+	abortEvaluationInterval = 5 * time.Second
+	defaultAbortWindow      = 30 * time.Second
+)
+
+// abortion is recorded in the annotation of the aborted job, so that it survives restarts of the controller
+type abortion struct {
+	Reason  string      `json:"reason"`
+	Message string      `json:"message"`
+	Time    metaV1.Time `json:"time"`
+}
+
+// getAbortion returns the abortion of the job, or nil when it has not been aborted
+func getAbortion(job *batchV1.Job) *abortion {
+	value, ok := job.Annotations[abortedAnnotation]
+	if !ok {
+		return nil
+	}
+	var a abortion
+	if err := json.Unmarshal([]byte(value), &a); err != nil {
+		// The job has been stopped anyway, even if the annotation is broken
+		return &abortion{Reason: "Aborted", Message: "Attack was aborted"}
+	}
+	return &a
+}
+
+// abortTracker remembers since when each abort condition of the running job has been exceeded
+type abortTracker struct {
+	mu      sync.Mutex
+	attacks map[types.NamespacedName]*abortState
+}
+
+type abortState struct {
+	job   types.UID
+	since map[int]time.Time
+}
+
+func newAbortTracker() *abortTracker {
+	return &abortTracker{
+		attacks: map[types.NamespacedName]*abortState{},
+	}
+}
+
+// exceeded records whether the condition at the index is exceeded now, and returns since when it has been exceeded
+func (t *abortTracker) exceeded(attack types.NamespacedName, job types.UID, index int, exceeded bool, now time.Time) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.attacks[attack]
+	if !ok || state.job != job {
+		state = &abortState{
+			job:   job,
+			since: map[int]time.Time{},
+		}
+		t.attacks[attack] = state
+	}
+	if !exceeded {
+		delete(state.since, index)
+		return time.Time{}
+	}
+	since, ok := state.since[index]
+	if !ok {
+		since = now
+		state.since[index] = since
+	}
+	return since
+}
+
+func (t *abortTracker) forget(attack types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.attacks, attack)
+}
+
+// evaluateAbortConditions returns the abortion by the first abort condition which has been exceeded for its duration,
+// or nil when none has
+func (r *AttackReconciler) evaluateAbortConditions(attack *vegetaV2.Attack, job *batchV1.Job, now time.Time) *abortion {
+	attackName := types.NamespacedName{Name: attack.Name, Namespace: attack.Namespace}
+
+	var met *abortion
+	for i, condition := range attack.Spec.AbortConditions {
+		window := defaultAbortWindow
+		if condition.Window != nil {
+			window = condition.Window.Duration
+		}
+		reason, violation := evaluateAbortCondition(condition, r.live.window(attackName, window))
+		// All conditions are tracked even after one is met, so that their durations are kept consistent
+		since := r.aborts.exceeded(attackName, job.UID, i, reason != "", now)
+		if reason == "" || met != nil {
+			continue
+		}
+		var duration time.Duration
+		if condition.For != nil {
+			duration = condition.For.Duration
+		}
+		if now.Sub(since) < duration {
+			continue
+		}
+		message := fmt.Sprintf("abortConditions[%d]: %s over the last %s", i, violation, window)
+		if duration > 0 {
+			message += fmt.Sprintf(" for %s", now.Sub(since).Round(time.Second))
+		}
+		met = &abortion{
+			Reason:  reason,
+			Message: message,
+			Time:    metaV1.NewTime(now),
+		}
+	}
+	return met
+}
+
+// buildAbortMonitoringCondition returns the condition of whether abort conditions are evaluated with the results
+// of all running attack pods, given the names of the pods whose results can no longer be followed
+func buildAbortMonitoringCondition(attack *vegetaV2.Attack, unfollowed []string) vegetaV2.Condition {
+	condition := vegetaV2.Condition{
+		Type:               vegetaV2.AttackAbortMonitoringAvailable,
+		Status:             metaV1.ConditionTrue,
+		ObservedGeneration: attack.Generation,
+		Reason:             "ResultsFollowed",
+	}
+	if len(unfollowed) > 0 {
+		sorted := append([]string(nil), unfollowed...)
+		sort.Strings(sorted)
+		condition.Status = metaV1.ConditionFalse
+		condition.Reason = "AbortMonitoringUnavailable"
+		condition.Message = fmt.Sprintf("Results of pods %s can not be followed, so that abort conditions are evaluated without them", strings.Join(sorted, ", "))
+	}
+	return condition
+}
+
+// maxAbortWindow returns the longest window of the abort conditions, or zero without them
+func maxAbortWindow(attack *vegetaV2.Attack) time.Duration {
+	var longest time.Duration
+	for _, condition := range attack.Spec.AbortConditions {
+		window := defaultAbortWindow
+		if condition.Window != nil {
+			window = condition.Window.Duration
+		}
+		if window > longest {
+			longest = window
+		}
+	}
+	return longest
+}
+
+// evaluateAbortCondition returns the reason and the violation of the first limit exceeded by the results in the window,
+// or empty strings when none is
+func evaluateAbortCondition(condition vegetaV2.AbortCondition, metrics *resultMetrics) (string, string) {
+	if metrics.requests == 0 {
+		return "", ""
+	}
+
+	if condition.MaxErrorRatio != "" {
+		// vegeta sets the error of results whose status codes are not successful, so that unsuccessful results are
+		// exactly those which are not OK
+		errorRatio := float64(metrics.failures) / float64(metrics.requests)
+		// The format has been validated by CRD
+		maxErrorRatio, _ := strconv.ParseFloat(condition.MaxErrorRatio, 64)
+		if errorRatio > maxErrorRatio {
+			return "MaxErrorRatioExceeded", fmt.Sprintf("maxErrorRatio: %.4f > %s", errorRatio, condition.MaxErrorRatio)
+		}
+	}
+
+	if condition.MaxLatencyP99 != nil {
+		if p99 := metrics.percentile(0.99); p99 > condition.MaxLatencyP99.Duration {
+			return "MaxLatencyP99Exceeded", fmt.Sprintf("maxLatencyP99: %s > %s", p99, condition.MaxLatencyP99.Duration)
+		}
+	}
+	if condition.MaxLatencyP95 != nil {
+		if p95 := metrics.percentile(0.95); p95 > condition.MaxLatencyP95.Duration {
+			return "MaxLatencyP95Exceeded", fmt.Sprintf("maxLatencyP95: %s > %s", p95, condition.MaxLatencyP95.Duration)
+		}
+	}
+	if condition.MaxLatencyMean != nil {
+		if mean := metrics.latencyTotal / time.Duration(metrics.requests); mean > condition.MaxLatencyMean.Duration {
+			return "MaxLatencyMeanExceeded", fmt.Sprintf("maxLatencyMean: %s > %s", mean, condition.MaxLatencyMean.Duration)
+		}
+	}
+	return "", ""
+}
+
+// abortJob records the abortion in the job and stops it in the same way as suspension,
+// so that the pods and their logs are kept with the partial results
+func (r *AttackReconciler) abortJob(ctx context.Context, logger logr.Logger, attack *vegetaV2.Attack, job *batchV1.Job, a *abortion) error {
+	patch := client.MergeFrom(job.DeepCopy())
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	aborted, err := computeJSON(a)
+	if err != nil {
+		return err
+	}
+	job.Annotations[abortedAnnotation] = aborted
+	job.Annotations[stopAnnotation] = stopReasonAborted
+	if err := r.Patch(ctx, job, patch); err != nil {
+		return err
+	}
+	r.Recorder.Eventf(attack, coreV1.EventTypeWarning, a.Reason, "Aborted job %q: %s", job.Name, a.Message)
+	logger.V(1).Info("abort", "job", job.Name, "reason", a.Reason)
+	return r.stopPods(ctx, logger, job)
+}
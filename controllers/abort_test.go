@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEvaluateAbortCondition(t *testing.T) {
+	durationOf := func(d time.Duration) *metaV1.Duration {
+		return &metaV1.Duration{Duration: d}
+	}
+
+	// 8 successful requests in 10ms and 2 failed requests in 100ms
+	metrics := newResultMetrics()
+	start := time.Unix(1500000000, 0)
+	for i := 0; i < 10; i++ {
+		r := &result{Timestamp: start.Add(time.Duration(i) * time.Second), Code: 200, Latency: 10 * time.Millisecond}
+		if i >= 8 {
+			r.Code = 500
+			r.Latency = 100 * time.Millisecond
+			r.Error = "500 Internal Server Error"
+		}
+		metrics.add(r)
+	}
+
+	tests := []struct {
+		name          string
+		condition     vegetaV2.AbortCondition
+		metrics       *resultMetrics
+		wantReason    string
+		wantViolation string
+	}{
+		{
+			name:      "no results",
+			condition: vegetaV2.AbortCondition{MaxErrorRatio: "0"},
+			metrics:   newResultMetrics(),
+		},
+		{
+			name: "within the limits",
+			condition: vegetaV2.AbortCondition{
+				MaxErrorRatio:  "0.2",
+				MaxLatencyP99:  durationOf(100 * time.Millisecond),
+				MaxLatencyP95:  durationOf(100 * time.Millisecond),
+				MaxLatencyMean: durationOf(30 * time.Millisecond),
+			},
+			metrics: metrics,
+		},
+		{
+			name: "error ratio exceeded first",
+			condition: vegetaV2.AbortCondition{
+				MaxErrorRatio: "0.1",
+				MaxLatencyP99: durationOf(50 * time.Millisecond),
+			},
+			metrics:       metrics,
+			wantReason:    "MaxErrorRatioExceeded",
+			wantViolation: "maxErrorRatio: 0.2000 > 0.1",
+		},
+		{
+			name:          "p99 exceeded",
+			condition:     vegetaV2.AbortCondition{MaxLatencyP99: durationOf(50 * time.Millisecond)},
+			metrics:       metrics,
+			wantReason:    "MaxLatencyP99Exceeded",
+			wantViolation: "maxLatencyP99: 100ms > 50ms",
+		},
+		{
+			name:          "p95 exceeded",
+			condition:     vegetaV2.AbortCondition{MaxLatencyP95: durationOf(50 * time.Millisecond)},
+			metrics:       metrics,
+			wantReason:    "MaxLatencyP95Exceeded",
+			wantViolation: "maxLatencyP95: 100ms > 50ms",
+		},
+		{
+			name:          "mean exceeded",
+			condition:     vegetaV2.AbortCondition{MaxLatencyMean: durationOf(20 * time.Millisecond)},
+			metrics:       metrics,
+			wantReason:    "MaxLatencyMeanExceeded",
+			wantViolation: "maxLatencyMean: 28ms > 20ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, violation := evaluateAbortCondition(tt.condition, tt.metrics)
+			if reason != tt.wantReason || violation != tt.wantViolation {
+				t.Errorf("evaluateAbortCondition() = (%q, %q), want (%q, %q)", reason, violation, tt.wantReason, tt.wantViolation)
+			}
+		})
+	}
+}
+
+func TestBuildAbortMonitoringCondition(t *testing.T) {
+	attack := &vegetaV2.Attack{}
+
+	tests := []struct {
+		name        string
+		unfollowed  []string
+		wantStatus  metaV1.ConditionStatus
+		wantReason  string
+		wantMessage string
+	}{
+		{
+			name:       "all followed",
+			wantStatus: metaV1.ConditionTrue,
+			wantReason: "ResultsFollowed",
+		},
+		{
+			name:        "some unfollowed",
+			unfollowed:  []string{"sample-attack-b", "sample-attack-a"},
+			wantStatus:  metaV1.ConditionFalse,
+			wantReason:  "AbortMonitoringUnavailable",
+			wantMessage: "Results of pods sample-attack-a, sample-attack-b can not be followed, so that abort conditions are evaluated without them",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildAbortMonitoringCondition(attack, tt.unfollowed)
+			if got.Type != vegetaV2.AttackAbortMonitoringAvailable || got.Status != tt.wantStatus || got.Reason != tt.wantReason || got.Message != tt.wantMessage {
+				t.Errorf("buildAbortMonitoringCondition() = %+v, want %s %s %q", got, tt.wantStatus, tt.wantReason, tt.wantMessage)
+			}
+		})
+	}
+}
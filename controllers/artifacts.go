@@ -0,0 +1,363 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	"github.com/go-logr/logr"
+	batchV1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// reportSourceAnnotation is the hash of the job and the reports which the report config maps are generated from
+	reportSourceAnnotation = "vegeta.kaidotdev.github.io/report-source"
+	// reportIndexAnnotation is where the reports are stored, which is kept in the first report config map
+	reportIndexAnnotation = "vegeta.kaidotdev.github.io/report-index"
+	// maxReportSize is the limit of the size of a report config map with a margin for metadata
+	maxReportSize = 1000 * 1000
+)
+
+// reconcileReport stores the reports of the finished attack into the report config maps, and returns the links to them
+// and the condition whether all of them are stored.
+// The reports are packed in order into "<name>-report" and the numbered ones after it, each of which is within the limit
+// of ConfigMap, and a report larger than the limit by itself is not stored.
+// The reports of the types which vegeta generates in each attack pod are stored for each pod with the keys prefixed by
+// the name of the pod.
+// The reports are generated only once for each job, since the results may not be available later.
+func (r *AttackReconciler) reconcileReport(ctx context.Context, logger logr.Logger, attack *vegetaV2.Attack, job *batchV1.Job, reports []vegetaV2.ReportOutput, total *resultMetrics, podArtifacts map[string]map[string]string) ([]vegetaV2.Artifact, vegetaV2.Condition, error) {
+	name := attack.Name + "-report"
+	condition := vegetaV2.Condition{
+		Type:               vegetaV2.AttackReportsStored,
+		Status:             metaV1.ConditionFalse,
+		ObservedGeneration: attack.Generation,
+	}
+	source, err := computeHash([]interface{}{job.UID, reports})
+	if err != nil {
+		return nil, condition, err
+	}
+
+	var current v1.ConfigMap
+	if err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: attack.Namespace}, &current); client.IgnoreNotFound(err) != nil {
+		return nil, condition, err
+	}
+	rendered, err := renderReports(reports, total, podArtifacts)
+	if err != nil {
+		return nil, condition, err
+	}
+	var index reportIndex
+	if current.Annotations[reportSourceAnnotation] != source || json.Unmarshal([]byte(current.Annotations[reportIndexAnnotation]), &index) != nil {
+		var previous reportIndex
+		_ = json.Unmarshal([]byte(current.Annotations[reportIndexAnnotation]), &previous)
+
+		var configMaps []*v1.ConfigMap
+		configMaps, index, err = buildReportConfigMaps(attack, rendered, source)
+		if err != nil {
+			return nil, condition, err
+		}
+		if len(index.Dropped) > 0 {
+			r.Recorder.Eventf(attack, v1.EventTypeWarning, "ReportTooLarge", "Reports %s exceed the limit of ConfigMap", strings.Join(index.Dropped, ", "))
+		}
+		// The first one which has the index is updated last, so that the reports are rendered again when any of them fails
+		for i := len(configMaps) - 1; i >= 0; i-- {
+			if err := r.reconcileConfigMap(ctx, logger, attack, configMaps[i], "report config map"); err != nil {
+				return nil, condition, err
+			}
+		}
+		for i := len(configMaps); i < previous.Parts; i++ {
+			stale := &v1.ConfigMap{
+				ObjectMeta: metaV1.ObjectMeta{
+					Name:      reportConfigMapName(attack, i),
+					Namespace: attack.Namespace,
+				},
+			}
+			if err := r.Delete(ctx, stale); client.IgnoreNotFound(err) != nil {
+				return nil, condition, err
+			}
+			r.Recorder.Eventf(attack, v1.EventTypeNormal, "SuccessfulDeleted", "Deleted config map: %q", stale.Name)
+		}
+	}
+
+	artifacts := make([]vegetaV2.Artifact, 0, len(rendered))
+	for _, report := range rendered {
+		configMapName, ok := index.ConfigMaps[report.key]
+		if !ok {
+			continue
+		}
+		artifacts = append(artifacts, vegetaV2.Artifact{
+			Type: report.output.Type,
+			Pod:  report.pod,
+			ConfigMapKeyRef: v1.ConfigMapKeySelector{
+				LocalObjectReference: v1.LocalObjectReference{
+					Name: configMapName,
+				},
+				Key: report.key,
+			},
+		})
+	}
+	if len(index.Dropped) > 0 {
+		condition.Reason = "ReportTooLarge"
+		condition.Message = fmt.Sprintf("Reports %s exceed the limit of ConfigMap and are not stored", strings.Join(index.Dropped, ", "))
+	} else {
+		condition.Status = metaV1.ConditionTrue
+		condition.Reason = "ReportsStored"
+		condition.Message = fmt.Sprintf("%d reports are stored in %d config maps", len(artifacts), index.Parts)
+	}
+	return artifacts, condition, nil
+}
+
+// reportIndex is where the reports are stored, which is kept in reportIndexAnnotation of the first report config map
+type reportIndex struct {
+	// Parts is the number of the report config maps
+	Parts int `json:"parts"`
+	// ConfigMaps is the name of the config map which has each key of the reports
+	ConfigMaps map[string]string `json:"configMaps"`
+	// Dropped is the keys of the reports which are too large to be stored
+	Dropped []string `json:"dropped,omitempty"`
+}
+
+// reportConfigMapName returns the name of the i-th report config map
+func reportConfigMapName(attack *vegetaV2.Attack, i int) string {
+	if i == 0 {
+		return attack.Name + "-report"
+	}
+	return fmt.Sprintf("%s-report-%d", attack.Name, i)
+}
+
+// isReportConfigMap returns true when the config map is one of the report config maps of the attack
+func isReportConfigMap(attack *vegetaV2.Attack, name string) bool {
+	if name == attack.Name+"-report" {
+		return true
+	}
+	i, err := strconv.Atoi(strings.TrimPrefix(name, attack.Name+"-report-"))
+	return err == nil && i > 0 && name == reportConfigMapName(attack, i)
+}
+
+// buildReportConfigMaps packs the rendered reports into the report config maps in order.
+// The first report config map is always built, so that the index is kept even when no reports are stored.
+func buildReportConfigMaps(attack *vegetaV2.Attack, reports []storedReport, source string) ([]*v1.ConfigMap, reportIndex, error) {
+	index := reportIndex{
+		ConfigMaps: map[string]string{},
+	}
+	var configMaps []*v1.ConfigMap
+	size := maxReportSize
+	for _, report := range reports {
+		if len(report.data) > maxReportSize {
+			index.Dropped = append(index.Dropped, report.key)
+			continue
+		}
+		if size+len(report.data) > maxReportSize {
+			configMaps = append(configMaps, &v1.ConfigMap{
+				ObjectMeta: metaV1.ObjectMeta{
+					Name:      reportConfigMapName(attack, len(configMaps)),
+					Namespace: attack.Namespace,
+					Annotations: map[string]string{
+						reportSourceAnnotation: source,
+					},
+				},
+				Data: map[string]string{},
+			})
+			size = 0
+		}
+		configMap := configMaps[len(configMaps)-1]
+		configMap.Data[report.key] = report.data
+		index.ConfigMaps[report.key] = configMap.Name
+		size += len(report.data)
+	}
+	if len(configMaps) == 0 {
+		configMaps = append(configMaps, &v1.ConfigMap{
+			ObjectMeta: metaV1.ObjectMeta{
+				Name:      reportConfigMapName(attack, 0),
+				Namespace: attack.Namespace,
+				Annotations: map[string]string{
+					reportSourceAnnotation: source,
+				},
+			},
+			Data: map[string]string{},
+		})
+	}
+	index.Parts = len(configMaps)
+	encoded, err := computeJSON(index)
+	if err != nil {
+		return nil, index, err
+	}
+	configMaps[0].Annotations[reportIndexAnnotation] = encoded
+	return configMaps, index, nil
+}
+
+// storedReport is a report to store in the report config maps
+type storedReport struct {
+	output *vegetaV2.ReportOutput
+	// pod is the name of the attack pod which generated the report, or empty for the report of all attack pods
+	pod  string
+	key  string
+	data string
+}
+
+// renderReports renders the reports of all attack pods from their merged results, and lists the reports generated by
+// vegeta in each attack pod in the order of the pods
+func renderReports(reports []vegetaV2.ReportOutput, total *resultMetrics, podArtifacts map[string]map[string]string) ([]storedReport, error) {
+	pods := make([]string, 0, len(podArtifacts))
+	for pod := range podArtifacts {
+		pods = append(pods, pod)
+	}
+	sort.Strings(pods)
+
+	var rendered []storedReport
+	for i := range reports {
+		output := &reports[i]
+		if !isPodReportType(output.Type) {
+			data, err := renderReport(output, total)
+			if err != nil {
+				return nil, err
+			}
+			rendered = append(rendered, storedReport{
+				output: output,
+				key:    output.Key(),
+				data:   data,
+			})
+			continue
+		}
+		for _, pod := range pods {
+			if data, ok := podArtifacts[pod][output.Key()]; ok {
+				rendered = append(rendered, storedReport{
+					output: output,
+					pod:    pod,
+					key:    pod + "." + output.Key(),
+					data:   data,
+				})
+			}
+		}
+	}
+	return rendered, nil
+}
+
+func renderReport(output *vegetaV2.ReportOutput, total *resultMetrics) (string, error) {
+	var buffer bytes.Buffer
+	switch output.Type {
+	case vegetaV2.JSONReportType:
+		encoded, err := computeJSON(total.vegetaMetrics())
+		if err != nil {
+			return "", err
+		}
+		buffer.WriteString(encoded)
+		buffer.WriteString("\n")
+	default:
+		writeTextReport(&buffer, total)
+	}
+	return buffer.String(), nil
+}
+
+// writeTextReport writes the report in the same format as `vegeta report -type=text`
+func writeTextReport(w io.Writer, m *resultMetrics) {
+	metrics := m.vegetaMetrics()
+	var min, p90 time.Duration
+	if m.requests > 0 {
+		min, p90 = m.latencyMin, m.percentile(0.90)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.StripEscape)
+	_, _ = fmt.Fprintf(tw, "Requests\t[total, rate, throughput]\t%d, %.2f, %.2f\n", metrics.Requests, metrics.Rate, metrics.Throughput)
+	_, _ = fmt.Fprintf(tw, "Duration\t[total, attack, wait]\t%s, %s, %s\n", metrics.Duration+metrics.Wait, metrics.Duration, metrics.Wait)
+	_, _ = fmt.Fprintf(
+		tw,
+		"Latencies\t[min, mean, 50, 90, 95, 99, max]\t%s, %s, %s, %s, %s, %s, %s\n",
+		min,
+		metrics.Latencies.Mean,
+		metrics.Latencies.P50,
+		p90,
+		metrics.Latencies.P95,
+		metrics.Latencies.P99,
+		metrics.Latencies.Max,
+	)
+	_, _ = fmt.Fprintf(tw, "Bytes In\t[total, mean]\t%d, %.2f\n", metrics.BytesIn.Total, metrics.BytesIn.Mean)
+	_, _ = fmt.Fprintf(tw, "Bytes Out\t[total, mean]\t%d, %.2f\n", metrics.BytesOut.Total, metrics.BytesOut.Mean)
+	_, _ = fmt.Fprintf(tw, "Success\t[ratio]\t%.2f%%\n", metrics.Success*100)
+	_, _ = fmt.Fprintf(tw, "Status Codes\t[code:count]\t")
+	codes := make([]string, 0, len(metrics.StatusCodes))
+	for code := range metrics.StatusCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		_, _ = fmt.Fprintf(tw, "%s:%d  ", code, metrics.StatusCodes[code])
+	}
+	_, _ = fmt.Fprintf(tw, "\nError Set:\n")
+	for _, e := range metrics.Errors {
+		_, _ = fmt.Fprintln(tw, e)
+	}
+	_ = tw.Flush()
+}
+
+// isPodReportType returns true when the reports of the type are generated by vegeta from the results of each attack
+// pod, since they can not be merged across pods exactly
+func isPodReportType(reportType vegetaV2.ReportType) bool {
+	switch reportType {
+	case vegetaV2.HistReportType, vegetaV2.HDRPlotReportType, vegetaV2.PlotReportType:
+		return true
+	default:
+		return false
+	}
+}
+
+// buildArtifactCommand returns the command of vegeta which generates the report from the results files in the attack pod,
+// or an empty string when the report is generated by the controller from the results of all attack pods
+func buildArtifactCommand(output *vegetaV2.ReportOutput, results []string) string {
+	files := strings.Join(results, " ")
+	switch output.Type {
+	case vegetaV2.HistReportType:
+		buckets := make([]string, 0, len(output.Buckets))
+		for _, bucket := range output.Buckets {
+			buckets = append(buckets, bucket.Duration.String())
+		}
+		return fmt.Sprintf("vegeta report -type %s %s", shellQuote("hist["+strings.Join(buckets, ",")+"]"), files)
+	case vegetaV2.HDRPlotReportType:
+		return fmt.Sprintf("vegeta report -type hdrplot %s", files)
+	case vegetaV2.PlotReportType:
+		var options []string
+		if output.Title != "" {
+			options = append(options, fmt.Sprintf("-title %s", shellQuote(output.Title)))
+		}
+		if output.Threshold != nil {
+			options = append(options, fmt.Sprintf("-threshold %d", *output.Threshold))
+		}
+		return strings.Join(append(append([]string{"vegeta plot"}, options...), files), " ")
+	default:
+		return ""
+	}
+}
+
+// buildArtifactScript returns the steps of the script of the attack pod which export the reports generated by vegeta
+// to results container, each of which is exported only when it is generated successfully.
+// They run after the termination message has been written, so that they never affect the report of the pod.
+func buildArtifactScript(attack *vegetaV2.Attack, results []string) []string {
+	var script []string
+	for _, output := range attack.Spec.Reports {
+		command := buildArtifactCommand(&output, results)
+		if command == "" {
+			continue
+		}
+		script = append(
+			script,
+			fmt.Sprintf(
+				"%s > /var/run/vegeta/artifact && { echo '%s%s'; cat /var/run/vegeta/artifact; echo; } >&4 || true",
+				command,
+				artifactHeader,
+				output.Key(),
+			),
+		)
+	}
+	return script
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildArtifactCommand(t *testing.T) {
+	threshold := int32(100)
+	results := []string{"/var/run/vegeta/results-0.bin", "/var/run/vegeta/results-1.bin"}
+
+	tests := []struct {
+		name   string
+		output vegetaV2.ReportOutput
+		want   string
+	}{
+		{
+			name:   "text of all attack pods",
+			output: vegetaV2.ReportOutput{Type: vegetaV2.TextReportType},
+		},
+		{
+			name: "hist",
+			output: vegetaV2.ReportOutput{
+				Type:    vegetaV2.HistReportType,
+				Buckets: []metaV1.Duration{{Duration: 0}, {Duration: 10 * time.Millisecond}, {Duration: time.Second}},
+			},
+			want: "vegeta report -type 'hist[0s,10ms,1s]' /var/run/vegeta/results-0.bin /var/run/vegeta/results-1.bin",
+		},
+		{
+			name:   "hdrplot",
+			output: vegetaV2.ReportOutput{Type: vegetaV2.HDRPlotReportType},
+			want:   "vegeta report -type hdrplot /var/run/vegeta/results-0.bin /var/run/vegeta/results-1.bin",
+		},
+		{
+			name:   "plot with the default options",
+			output: vegetaV2.ReportOutput{Type: vegetaV2.PlotReportType},
+			want:   "vegeta plot /var/run/vegeta/results-0.bin /var/run/vegeta/results-1.bin",
+		},
+		{
+			name:   "plot with the options",
+			output: vegetaV2.ReportOutput{Type: vegetaV2.PlotReportType, Title: "Sample's plot", Threshold: &threshold},
+			want:   `vegeta plot -title 'Sample'\''s plot' -threshold 100 /var/run/vegeta/results-0.bin /var/run/vegeta/results-1.bin`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildArtifactCommand(&tt.output, results); got != tt.want {
+				t.Errorf("buildArtifactCommand() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderReports(t *testing.T) {
+	reports := []vegetaV2.ReportOutput{
+		{Type: vegetaV2.JSONReportType},
+		{Type: vegetaV2.PlotReportType},
+	}
+	podArtifacts := map[string]map[string]string{
+		"sample-attack-b": {"plot.html": "<html>b</html>"},
+		"sample-attack-a": {"plot.html": "<html>a</html>"},
+		// The pod whose plot failed to be generated
+		"sample-attack-c": {},
+	}
+
+	rendered, err := renderReports(reports, newResultMetrics(), podArtifacts)
+	if err != nil {
+		t.Fatalf("renderReports() error = %v", err)
+	}
+	var got []string
+	for _, report := range rendered {
+		got = append(got, report.pod+"/"+report.key)
+	}
+	want := []string{"/json.json", "sample-attack-a/sample-attack-a.plot.html", "sample-attack-b/sample-attack-b.plot.html"}
+	if len(got) != len(want) {
+		t.Fatalf("renderReports() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("renderReports() = %q, want %q", got, want)
+		}
+	}
+	if rendered[1].data != "<html>a</html>" {
+		t.Errorf("renderReports() data = %q, want the plot of sample-attack-a", rendered[1].data)
+	}
+}
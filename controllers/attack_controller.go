@@ -2,27 +2,45 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"sort"
 	"strings"
+	"time"
 
-	vegetaV1 "vegeta-controller/api/v1"
+	vegetaV2 "vegeta-controller/api/v2"
 
 	"github.com/go-logr/logr"
 	batchV1 "k8s.io/api/batch/v1"
 	coreV1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	toolsCache "k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
-	ownerKey           = ".metadata.controller"
-	defaultVegetaImage = "peterevans/vegeta:6.7"
+	ownerKey               = ".metadata.controller"
+	attackLabel            = "vegeta.kaidotdev.github.io/attack"
+	specHashAnnotation     = "vegeta.kaidotdev.github.io/spec-hash"
+	scenarioHashAnnotation = "vegeta.kaidotdev.github.io/scenario-hash"
+	defaultVegetaImage     = "peterevans/vegeta:6.7"
 )
 
 type AttackReconciler struct {
@@ -30,94 +48,814 @@ type AttackReconciler struct {
 	Log         logr.Logger
 	Scheme      *runtime.Scheme
 	Recorder    record.EventRecorder
+	Clientset   kubernetes.Interface
 	VegetaImage string
+	// DefaultTTLSecondsAfterFinished is used for Attack without ttlSecondsAfterFinished, which is never cleaned up when nil
+	DefaultTTLSecondsAfterFinished *int32
+	// FinalizeTimeout is how long the deleted Attack waits for its pods to stop before releasing the finalizer
+	FinalizeTimeout time.Duration
+	// APIReader reads Secrets from the API server directly, not to cache the data of every Secret of the cluster
+	APIReader client.Reader
+
+	results *resultStore
+	live    *liveStreamer
+	aborts  *abortTracker
 }
 
 func (r *AttackReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	attack := &vegetaV1.Attack{}
+	attack := &vegetaV2.Attack{}
 	ctx := context.Background()
 	logger := r.Log.WithValues("attack", req.NamespacedName)
 	if err := r.Get(ctx, req.NamespacedName, attack); err != nil {
 		if errors.IsNotFound(err) {
+			r.results.forget(req.NamespacedName)
+			r.live.forget(req.NamespacedName)
+			r.aborts.forget(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
 	}
 
+	if attack.DeletionTimestamp != nil {
+		return r.finalize(ctx, logger, attack)
+	}
+	if !hasFinalizer(attack, resultsFinalizer) {
+		controllerutil.AddFinalizer(attack, resultsFinalizer)
+		if err := r.Update(ctx, attack); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if isAttackCleanedUp(attack) {
+		// The job is not created again until the spec changes
+		return ctrl.Result{}, nil
+	}
+
 	if err := r.cleanupOwnedResources(ctx, attack); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	var job batchV1.Job
-	if err := r.Client.Get(
-		ctx,
-		client.ObjectKey{
-			Name:      req.Name + "-attack",
-			Namespace: req.Namespace,
-		},
-		&job,
-	); errors.IsNotFound(err) {
-		job = *r.buildJob(attack)
-		if err := controllerutil.SetControllerReference(attack, &job, r.Scheme); err != nil {
+	scenarioHash, scenarioAvailable, err := r.reconcileScenario(ctx, logger, attack)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	bodiesHash, bodiesAvailable, err := r.reconcileBodies(ctx, logger, attack)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	tlsHash, tlsAvailable, err := r.reconcileTLS(ctx, attack)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	available := true
+	for _, condition := range []vegetaV2.Condition{scenarioAvailable, bodiesAvailable, tlsAvailable} {
+		if condition.Status == metaV1.ConditionTrue {
+			continue
+		}
+		available = false
+		previous := vegetaV2.FindCondition(attack.Status.Conditions, condition.Type)
+		if previous == nil || previous.Status == metaV1.ConditionTrue {
+			r.Recorder.Event(attack, coreV1.EventTypeWarning, condition.Reason, condition.Message)
+		}
+	}
+	if !available {
+		// Keep the existing job as it is until the scenario, the bodies and the certificates become available again
+		var job batchV1.Job
+		if err := r.Get(ctx, client.ObjectKey{Name: req.Name + "-attack", Namespace: req.Namespace}, &job); client.IgnoreNotFound(err) != nil {
 			return ctrl.Result{}, err
 		}
-		if err := r.Create(ctx, &job); err != nil && !errors.IsAlreadyExists(err) {
+		if err := r.updateStatus(ctx, logger, attack, &job, attack.Spec.Reports, scenarioAvailable, bodiesAvailable, tlsAvailable); err != nil {
 			return ctrl.Result{}, err
 		}
-		r.Recorder.Eventf(attack, coreV1.EventTypeNormal, "SuccessfulCreated", "Created job: %q", job.Name)
-		logger.V(1).Info("create", "job", job)
-	} else if err != nil {
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.reconcileConfigMap(ctx, logger, attack, r.buildNSSwitchConfigMap(attack), "nsswitch config map"); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	var scenarioConfigMap v1.ConfigMap
+	desired, err := r.buildJob(attack)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	desired.Spec.Template.Annotations[scenarioHashAnnotation] = scenarioHash
+	if bodiesHash != "" {
+		desired.Spec.Template.Annotations[bodiesHashAnnotation] = bodiesHash
+	}
+	if tlsHash != "" {
+		desired.Spec.Template.Annotations[tlsHashAnnotation] = tlsHash
+	}
+	job, err := r.reconcileJob(ctx, logger, attack, desired)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if job == nil {
+		// The job is being replaced, the deletion event of it triggers next reconciliation
+		return ctrl.Result{}, nil
+	}
+	specApplied := buildSpecAppliedCondition(attack, job, desired)
+	if specApplied.Status == metaV1.ConditionFalse {
+		// The event is emitted only when the replacement is refused first, not on every reconciliation
+		previous := vegetaV2.FindCondition(attack.Status.Conditions, vegetaV2.AttackSpecApplied)
+		if previous == nil || previous.Status != metaV1.ConditionFalse {
+			r.Recorder.Eventf(attack, coreV1.EventTypeWarning, specApplied.Reason, "%s", specApplied.Message)
+		}
+	}
+
+	// Abort conditions are evaluated periodically while the attack is running, since results never trigger reconciliation
+	evaluating := len(attack.Spec.AbortConditions) > 0 && job.Status.Active > 0 && !isJobFinished(job) && !isJobStopped(job)
+	if evaluating {
+		if a := r.evaluateAbortConditions(attack, job, time.Now()); a != nil {
+			if err := r.abortJob(ctx, logger, attack, job, a); err != nil {
+				return ctrl.Result{}, err
+			}
+			evaluating = false
+		}
+	}
+
+	if err := r.reconcileStartBarrier(ctx, logger, attack, job); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateStatus(ctx, logger, attack, job, attack.Spec.Reports, scenarioAvailable, bodiesAvailable, tlsAvailable, specApplied); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if evaluating {
+		return ctrl.Result{RequeueAfter: abortEvaluationInterval}, nil
+	}
+	if after := untilSchedule(attack, job); after > 0 {
+		return ctrl.Result{RequeueAfter: after}, nil
+	}
+	return r.reconcileTTL(ctx, logger, attack)
+}
+
+func (r *AttackReconciler) reconcileConfigMap(ctx context.Context, logger logr.Logger, attack *vegetaV2.Attack, desired *v1.ConfigMap, description string) error {
+	if desired.Annotations == nil {
+		desired.Annotations = map[string]string{}
+	}
+	hash, err := computeConfigMapHash(desired)
+	if err != nil {
+		return err
+	}
+	desired.Annotations[specHashAnnotation] = hash
+	if err := controllerutil.SetControllerReference(attack, desired, r.Scheme); err != nil {
+		return err
+	}
+
+	var configMap v1.ConfigMap
 	if err := r.Client.Get(
 		ctx,
 		client.ObjectKey{
-			Name:      req.Name + "-scenario",
-			Namespace: req.Namespace,
+			Name:      desired.Name,
+			Namespace: desired.Namespace,
 		},
-		&scenarioConfigMap,
+		&configMap,
 	); errors.IsNotFound(err) {
-		scenarioConfigMap = *r.buildScenarioConfigMap(attack)
-		if err := controllerutil.SetControllerReference(attack, &scenarioConfigMap, r.Scheme); err != nil {
-			return ctrl.Result{}, err
-		}
-		if err := r.Create(ctx, &scenarioConfigMap); err != nil && !errors.IsAlreadyExists(err) {
-			return ctrl.Result{}, err
+		if err := r.Create(ctx, desired); err != nil && !errors.IsAlreadyExists(err) {
+			return err
 		}
-		r.Recorder.Eventf(attack, coreV1.EventTypeNormal, "SuccessfulCreated", "Created scenario config map: %q", scenarioConfigMap.Name)
-		logger.V(1).Info("create", "scenario config map", scenarioConfigMap)
+		r.Recorder.Eventf(attack, coreV1.EventTypeNormal, "SuccessfulCreated", "Created %s: %q", description, desired.Name)
+		logger.V(1).Info("create", description, desired.Name)
+		return nil
 	} else if err != nil {
-		return ctrl.Result{}, err
+		return err
 	}
 
-	var nsswitchConfigMap v1.ConfigMap
+	// Both spec changes and direct edits of the config map are detected, and the latter is reverted
+	current, err := computeConfigMapHash(&configMap)
+	if err != nil {
+		return err
+	}
+	if applied := configMap.Annotations[specHashAnnotation]; applied == hash && applied == current {
+		return nil
+	}
+
+	if configMap.Annotations == nil {
+		configMap.Annotations = map[string]string{}
+	}
+	for k, v := range desired.Annotations {
+		configMap.Annotations[k] = v
+	}
+	configMap.Data = desired.Data
+	configMap.BinaryData = desired.BinaryData
+	if err := r.Update(ctx, &configMap); err != nil {
+		return err
+	}
+	r.Recorder.Eventf(attack, coreV1.EventTypeNormal, "SuccessfulUpdated", "Updated %s: %q", description, configMap.Name)
+	logger.V(1).Info("update", description, configMap.Name)
+	return nil
+}
+
+// reconcileJob converges the attack job to the desired one.
+// It returns nil job when the job is being replaced.
+func (r *AttackReconciler) reconcileJob(ctx context.Context, logger logr.Logger, attack *vegetaV2.Attack, desired *batchV1.Job) (*batchV1.Job, error) {
+	// Pod template of job is immutable, so only it is hashed and parallelism is compared directly
+	if desired.Annotations == nil {
+		desired.Annotations = map[string]string{}
+	}
+	hash, err := computeHash(desired.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+	desired.Annotations[specHashAnnotation] = hash
+	if value, ok := attack.Annotations[vegetaV2.CancelAnnotation]; ok {
+		// The job is cancelled only when the annotation is changed after it is created
+		desired.Annotations[vegetaV2.CancelAnnotation] = value
+	}
+	if err := controllerutil.SetControllerReference(attack, desired, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	var job batchV1.Job
 	if err := r.Client.Get(
 		ctx,
 		client.ObjectKey{
-			Name:      req.Name + "-nsswitch",
-			Namespace: req.Namespace,
+			Name:      desired.Name,
+			Namespace: desired.Namespace,
 		},
-		&nsswitchConfigMap,
+		&job,
 	); errors.IsNotFound(err) {
-		nsswitchConfigMap = *r.buildNSSwitchConfigMap(attack)
-		if err := controllerutil.SetControllerReference(attack, &nsswitchConfigMap, r.Scheme); err != nil {
-			return ctrl.Result{}, err
+		if attack.Spec.Suspend {
+			// The job is not created until the attack is resumed
+			return &batchV1.Job{}, nil
 		}
-		if err := r.Create(ctx, &nsswitchConfigMap); err != nil && !errors.IsAlreadyExists(err) {
-			return ctrl.Result{}, err
+		if isAttackScheduled(attack) || isDeadlineExceeded(attack) {
+			// The job is created at startAt, and never after the deadline
+			return &batchV1.Job{}, nil
+		}
+		if err := r.Create(ctx, desired); err != nil && !errors.IsAlreadyExists(err) {
+			return nil, err
 		}
-		r.Recorder.Eventf(attack, coreV1.EventTypeNormal, "SuccessfulCreated", "Created nsswitch config map: %q", nsswitchConfigMap.Name)
-		logger.V(1).Info("create", "nsswitch config map", nsswitchConfigMap)
+		r.Recorder.Eventf(attack, coreV1.EventTypeNormal, "SuccessfulCreated", "Created job: %q", desired.Name)
+		logger.V(1).Info("create", "job", desired.Name)
+		return desired, nil
 	} else if err != nil {
-		return ctrl.Result{}, err
+		return nil, err
+	}
+
+	if job.DeletionTimestamp != nil {
+		return nil, nil
+	}
+
+	if getStopReason(&job) == stopReasonSuspended && !attack.Spec.Suspend {
+		if err := r.Delete(ctx, &job, client.PropagationPolicy(metaV1.DeletePropagationForeground)); err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		r.Recorder.Eventf(attack, coreV1.EventTypeNormal, "SuccessfulDeleted", "Deleted suspended job to start the attack again: %q", job.Name)
+		logger.V(1).Info("resume", "job", job.Name)
+		return nil, nil
+	}
+
+	hash, ok := job.Annotations[specHashAnnotation]
+	if !ok {
+		// Adopt the job created before spec hash was introduced instead of restarting the attack
+		patch := client.MergeFrom(job.DeepCopy())
+		if job.Annotations == nil {
+			job.Annotations = map[string]string{}
+		}
+		job.Annotations[specHashAnnotation] = desired.Annotations[specHashAnnotation]
+		if err := r.Patch(ctx, &job, patch); err != nil {
+			return nil, err
+		}
+	} else if hash != desired.Annotations[specHashAnnotation] {
+		// The aborted or stopped job has stopped as well as the finished one
+		if !isJobFinished(&job) && !isJobStopped(&job) && attack.Spec.ReplacePolicy != vegetaV2.RestartReplacePolicy {
+			// The refusal is reported by SpecApplied condition
+			return &job, nil
+		}
+
+		if err := r.Delete(ctx, &job, client.PropagationPolicy(metaV1.DeletePropagationForeground)); err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		r.Recorder.Eventf(attack, coreV1.EventTypeNormal, "SuccessfulDeleted", "Deleted job to apply spec changes: %q", job.Name)
+		logger.V(1).Info("replace", "job", job.Name)
+		return nil, nil
+	}
+
+	if !isJobFinished(&job) && !isJobStopped(&job) {
+		// Cancellation takes precedence, since the cancelled job is never started again unlike the suspended one
+		if isCancelRequested(attack, &job) {
+			if err := r.stopJob(ctx, logger, attack, &job, stopReasonCancelled); err != nil {
+				return nil, err
+			}
+		} else if attack.Spec.Suspend {
+			if err := r.stopJob(ctx, logger, attack, &job, stopReasonSuspended); err != nil {
+				return nil, err
+			}
+		} else if isDeadlineExceeded(attack) {
+			if err := r.stopJob(ctx, logger, attack, &job, stopReasonDeadlineExceeded); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if isJobStopped(&job) {
+		// The stopped job, including the aborted one, is never scaled up again.
+		// Pods are annotated until all of them have stopped, since the job may create pods after it is stopped
+		if job.Status.Active > 0 {
+			if err := r.stopPods(ctx, logger, &job); err != nil {
+				return nil, err
+			}
+		}
+		return &job, nil
 	}
 
-	return ctrl.Result{}, nil
+	if job.Spec.Parallelism == nil || *job.Spec.Parallelism != *desired.Spec.Parallelism {
+		patch := client.MergeFrom(job.DeepCopy())
+		job.Spec.Parallelism = desired.Spec.Parallelism
+		if err := r.Patch(ctx, &job, patch); err != nil {
+			return nil, err
+		}
+		r.Recorder.Eventf(attack, coreV1.EventTypeNormal, "SuccessfulScaled", "Scaled job %q to parallelism %d", job.Name, *job.Spec.Parallelism)
+		logger.V(1).Info("scale", "job", job.Name, "parallelism", *job.Spec.Parallelism)
+	}
+
+	return &job, nil
 }
 
-func (r *AttackReconciler) buildScenarioConfigMap(attack *vegetaV1.Attack) *v1.ConfigMap {
+// buildSpecAppliedCondition returns whether the job runs the current spec, which is False while the replacement of the
+// running job is refused by replacePolicy
+func buildSpecAppliedCondition(attack *vegetaV2.Attack, job *batchV1.Job, desired *batchV1.Job) vegetaV2.Condition {
+	condition := vegetaV2.Condition{
+		Type:               vegetaV2.AttackSpecApplied,
+		Status:             metaV1.ConditionTrue,
+		ObservedGeneration: attack.Generation,
+		Reason:             "SpecApplied",
+	}
+	if job.UID != "" && job.Annotations[specHashAnnotation] != desired.Annotations[specHashAnnotation] {
+		condition.Status = metaV1.ConditionFalse
+		condition.Reason = "ReplaceRefused"
+		condition.Message = fmt.Sprintf("Refused to replace job %q while the attack is running, which is replaced after it has finished", job.Name)
+	}
+	return condition
+}
+
+// updateStatus mirrors the job and its pods into status of Attack along with the given conditions,
+// and exports the given reports once the attack has finished
+func (r *AttackReconciler) updateStatus(ctx context.Context, logger logr.Logger, attack *vegetaV2.Attack, job *batchV1.Job, reports []vegetaV2.ReportOutput, conditions ...vegetaV2.Condition) error {
+	var pods v1.PodList
+	if job.UID != "" {
+		if err := r.List(
+			ctx,
+			&pods,
+			client.InNamespace(attack.Namespace),
+			client.MatchingLabels{"controller-uid": string(job.UID)},
+		); err != nil {
+			return err
+		}
+	}
+
+	status := attack.Status.DeepCopy()
+	status.ObservedGeneration = attack.Generation
+	status.StartTime = job.Status.StartTime
+	status.CompletionTime = job.Status.CompletionTime
+	status.Active = job.Status.Active
+	status.Succeeded = job.Status.Succeeded
+	status.Failed = job.Status.Failed
+
+	podOption, err := getPodOption(attack, job)
+	if err != nil {
+		logger.Error(err, "unable to decode pod option", "job", job.Name)
+	}
+	status.PodOption = podOption
+
+	attackName := types.NamespacedName{Name: attack.Name, Namespace: attack.Namespace}
+	abortion := getAbortion(job)
+	r.results.retain(attackName, pods.Items)
+	r.live.retain(attackName, pods.Items)
+
+	var running, alive int32
+	var collected int
+	collectedPods := map[types.UID]struct{}{}
+	starts := r.live.starts(attackName)
+	followed := r.live.collect(attackName)
+	exhausted := r.live.exhausted(attackName)
+	var monitored bool
+	var unfollowed []string
+	var unsupported []string
+	var truncated []string
+	var probed bool
+	summary := newResultMetrics()
+	stageSummaries := map[string]*resultMetrics{}
+	podArtifacts := map[string]map[string]string{}
+	status.Pods = make([]vegetaV2.AttackPodStatus, 0, len(pods.Items))
+	zones := r.newZoneResolver(logger)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == v1.PodRunning {
+			running++
+		}
+		if pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed {
+			alive++
+		}
+
+		podStatus := vegetaV2.AttackPodStatus{
+			Name:     pod.Name,
+			Phase:    pod.Status.Phase,
+			NodeName: pod.Spec.NodeName,
+			Zone:     zones.lookup(ctx, pod.Spec.NodeName),
+		}
+		collectPodMetrics(&podStatus, pod.Status.ContainerStatuses)
+		if isContainerRunning(pod, resultsContainerName) {
+			r.live.watch(r.Clientset, attack, pod)
+			monitored = true
+			if _, ok := exhausted[pod.UID]; ok {
+				unfollowed = append(unfollowed, pod.Name)
+			}
+		}
+		if flags, ok := findUnsupportedOptions(pod.Status.ContainerStatuses); ok {
+			probed = true
+			if flags != "" {
+				unsupported = append(unsupported, flags)
+			}
+		}
+		if isContainerTerminated(pod, resultsContainerName) {
+			results, ok := followed[pod.UID]
+			written, counted := countWrittenResults(pod)
+			if !ok || (counted && results.total().requests != written) {
+				// The log is read again only when the results have not been followed to the end, e.g. after restarts
+				results, err = r.results.load(r.Clientset, attackName, pod)
+			}
+			if err != nil {
+				logger.Error(err, "unable to read results", "pod", pod.Name)
+				podStatus.Message = fmt.Sprintf("failed to read results: %s", err)
+			} else if read := results.total().requests; counted && read != written {
+				// The report of vegeta in the termination message is kept for the pod, but the partial results are
+				// never merged into the report of the attack
+				podStatus.Message = fmt.Sprintf("only %d of %d results were read from the log of %s container, which may have been rotated", read, written, resultsContainerName)
+				truncated = append(truncated, pod.Name)
+				collectedPods[pod.UID] = struct{}{}
+			} else {
+				// Prefer exact metrics to the report estimated by vegeta
+				metrics := results.total()
+				podStatus.Report = metrics.vegetaMetrics().toReport()
+				if !metrics.earliest.IsZero() {
+					starts[pod.UID] = metrics.earliest
+				}
+				summary.merge(metrics)
+				for name, stageMetrics := range results.stages {
+					if _, ok := stageSummaries[name]; !ok {
+						stageSummaries[name] = newResultMetrics()
+					}
+					stageSummaries[name].merge(stageMetrics)
+				}
+				if len(results.artifacts) > 0 {
+					podArtifacts[pod.Name] = results.artifacts
+				}
+				collected++
+				collectedPods[pod.UID] = struct{}{}
+			}
+		}
+		status.Pods = append(status.Pods, podStatus)
+	}
+	allCollected := len(collectedPods) == len(pods.Items)
+	sort.Slice(status.Pods, func(i, j int) bool {
+		return status.Pods[i].Name < status.Pods[j].Name
+	})
+	if len(status.Pods) == 0 {
+		status.Pods = nil
+	}
+	status.Report = nil
+	var summaryMetrics *vegetaMetrics
+	if collected > 0 && len(truncated) == 0 {
+		summaryMetrics = summary.vegetaMetrics()
+		status.Report = summaryMetrics.toReport()
+	}
+	status.StartAt, err = r.getStartAt(ctx, attack, job)
+	if err != nil {
+		logger.Error(err, "unable to read start barrier", "job", job.Name)
+	}
+	status.StartSkew = nil
+	if len(starts) > 0 {
+		var earliest, latest time.Time
+		for _, start := range starts {
+			if earliest.IsZero() || start.Before(earliest) {
+				earliest = start
+			}
+			if start.After(latest) {
+				latest = start
+			}
+		}
+		status.StartSkew = &metaV1.Duration{Duration: latest.Sub(earliest)}
+	}
+
+	stopped := isJobFinished(job)
+	// The deleted Attack exports the results collected until then without waiting for the rest after the timeout
+	expired := r.isFinalizeExpired(attack)
+	if len(reports) == 0 {
+		status.Artifacts = nil
+	} else if (stopped || expired) && collected > 0 && len(truncated) == 0 && (allCollected || expired) {
+		// Artifacts of the previous attack are kept until all results of this attack are collected
+		artifacts, reportsStored, err := r.reconcileReport(ctx, logger, attack, job, reports, summary, podArtifacts)
+		if err != nil {
+			return err
+		}
+		status.Artifacts = artifacts
+		vegetaV2.SetCondition(&status.Conditions, reportsStored)
+	}
+	status.Stages = nil
+	for i := range attack.Spec.Stages {
+		name := stageName(i)
+		if stageMetrics, ok := stageSummaries[name]; ok && len(truncated) == 0 {
+			status.Stages = append(status.Stages, vegetaV2.StageStatus{
+				Name:   name,
+				Report: stageMetrics.vegetaMetrics().toReport(),
+			})
+		}
+	}
+
+	jobComplete := findJobCondition(job, batchV1.JobComplete)
+	jobFailed := findJobCondition(job, batchV1.JobFailed)
+	stopReason := getStopReason(job)
+	if attack.Spec.Suspend && job.UID == "" {
+		// The job has not been created since the attack was suspended
+		stopReason = stopReasonSuspended
+	} else if job.UID == "" && isDeadlineExceeded(attack) {
+		// The job has not been created by the deadline
+		stopReason = stopReasonDeadlineExceeded
+	}
+
+	ready := vegetaV2.Condition{
+		Type:               vegetaV2.AttackReady,
+		Status:             metaV1.ConditionFalse,
+		ObservedGeneration: attack.Generation,
+		Reason:             "PodsPending",
+		Message:            fmt.Sprintf("%d/%d attack pods are running", running, attack.Spec.Parallelism),
+	}
+	complete := vegetaV2.Condition{
+		Type:               vegetaV2.AttackComplete,
+		Status:             metaV1.ConditionFalse,
+		ObservedGeneration: attack.Generation,
+		Reason:             "JobNotComplete",
+	}
+	failed := vegetaV2.Condition{
+		Type:               vegetaV2.AttackFailure,
+		Status:             metaV1.ConditionFalse,
+		ObservedGeneration: attack.Generation,
+		Reason:             "JobNotFailed",
+	}
+
+	switch {
+	case abortion != nil:
+		// The job completes after it is aborted, since the pods exit successfully on the stop annotation
+		status.Phase = vegetaV2.AttackAborted
+		ready.Reason = "AttackAborted"
+	case stopReason == stopReasonCancelled:
+		status.Phase = vegetaV2.AttackCancelled
+		ready.Reason = "AttackCancelled"
+	case stopReason == stopReasonSuspended:
+		status.Phase = vegetaV2.AttackSuspended
+		ready.Reason = "AttackSuspended"
+	case stopReason == stopReasonDeadlineExceeded && job.UID == "":
+		status.Phase = vegetaV2.AttackFailed
+		ready.Reason = "AttackFinished"
+		failed.Status = metaV1.ConditionTrue
+		failed.Reason = stopReasonDeadlineExceeded
+		failed.Message = fmt.Sprintf("Attack did not start by the deadline %s", attack.Spec.Deadline.UTC().Format(time.RFC3339))
+	case stopReason == stopReasonDeadlineExceeded && (jobComplete != nil || jobFailed != nil):
+		// The pods stopped at the deadline exit successfully, whose results are reported as usual
+		status.Phase = vegetaV2.AttackFailed
+		ready.Reason = "AttackFinished"
+		failed.Status = metaV1.ConditionTrue
+		failed.Reason = stopReasonDeadlineExceeded
+		failed.Message = fmt.Sprintf("Attack was stopped at the deadline %s", attack.Spec.Deadline.UTC().Format(time.RFC3339))
+		if jobComplete != nil {
+			failed.LastTransitionTime = jobComplete.LastTransitionTime
+		} else {
+			failed.LastTransitionTime = jobFailed.LastTransitionTime
+		}
+		if status.CompletionTime == nil {
+			status.CompletionTime = &failed.LastTransitionTime
+		}
+	case job.UID == "" && isAttackScheduled(attack):
+		status.Phase = vegetaV2.AttackScheduled
+		ready.Reason = "AttackScheduled"
+		ready.Message = fmt.Sprintf("Attack starts at %s", attack.Spec.StartAt.UTC().Format(time.RFC3339))
+	case jobComplete != nil:
+		status.Phase = vegetaV2.AttackSucceeded
+		ready.Reason = "AttackFinished"
+		complete.Status = metaV1.ConditionTrue
+		complete.Reason = "JobComplete"
+		complete.Message = jobComplete.Message
+		complete.LastTransitionTime = jobComplete.LastTransitionTime
+	case jobFailed != nil:
+		status.Phase = vegetaV2.AttackFailed
+		ready.Reason = "AttackFinished"
+		failed.Status = metaV1.ConditionTrue
+		failed.Reason = jobFailed.Reason
+		failed.Message = jobFailed.Message
+		failed.LastTransitionTime = jobFailed.LastTransitionTime
+		if status.CompletionTime == nil {
+			status.CompletionTime = &jobFailed.LastTransitionTime
+		}
+	case running > 0:
+		status.Phase = vegetaV2.AttackRunning
+		if running >= attack.Spec.Parallelism {
+			ready.Status = metaV1.ConditionTrue
+			ready.Reason = "PodsRunning"
+		}
+	default:
+		status.Phase = vegetaV2.AttackPending
+	}
+	vegetaV2.SetCondition(&status.Conditions, ready)
+	vegetaV2.SetCondition(&status.Conditions, complete)
+	vegetaV2.SetCondition(&status.Conditions, failed)
+	if len(attack.Spec.AbortConditions) > 0 || abortion != nil {
+		aborted := vegetaV2.Condition{
+			Type:               vegetaV2.AttackAbortion,
+			Status:             metaV1.ConditionFalse,
+			ObservedGeneration: attack.Generation,
+			Reason:             "NotAborted",
+		}
+		if abortion != nil {
+			aborted.Status = metaV1.ConditionTrue
+			aborted.Reason = abortion.Reason
+			aborted.Message = abortion.Message
+			aborted.LastTransitionTime = abortion.Time
+		}
+		vegetaV2.SetCondition(&status.Conditions, aborted)
+	}
+	if len(attack.Spec.AbortConditions) > 0 && monitored {
+		// The condition is kept after the pods have finished, so that it tells whether some results were missed
+		monitoring := buildAbortMonitoringCondition(attack, unfollowed)
+		previous := vegetaV2.FindCondition(attack.Status.Conditions, vegetaV2.AttackAbortMonitoringAvailable)
+		if monitoring.Status == metaV1.ConditionFalse && (previous == nil || previous.Status != metaV1.ConditionFalse) {
+			r.Recorder.Eventf(attack, coreV1.EventTypeWarning, monitoring.Reason, "%s", monitoring.Message)
+		}
+		vegetaV2.SetCondition(&status.Conditions, monitoring)
+	}
+	if attack.Spec.Suspend || stopReason == stopReasonSuspended || vegetaV2.FindCondition(attack.Status.Conditions, vegetaV2.AttackSuspension) != nil {
+		suspended := vegetaV2.Condition{
+			Type:               vegetaV2.AttackSuspension,
+			Status:             metaV1.ConditionFalse,
+			ObservedGeneration: attack.Generation,
+		}
+		switch {
+		case stopReason == stopReasonSuspended && alive > 0:
+			suspended.Reason = "Suspending"
+			suspended.Message = fmt.Sprintf("Waiting for %d attack pods to stop", alive)
+		case stopReason == stopReasonSuspended:
+			suspended.Status = metaV1.ConditionTrue
+			suspended.Reason = "Suspended"
+		case attack.Spec.Suspend:
+			suspended.Reason = "AttackStopped"
+			suspended.Message = "Attack had stopped before it was suspended"
+		default:
+			suspended.Reason = "Resumed"
+		}
+		vegetaV2.SetCondition(&status.Conditions, suspended)
+	}
+	if stopReason == stopReasonCancelled || vegetaV2.FindCondition(attack.Status.Conditions, vegetaV2.AttackCancellation) != nil {
+		cancelled := vegetaV2.Condition{
+			Type:               vegetaV2.AttackCancellation,
+			Status:             metaV1.ConditionFalse,
+			ObservedGeneration: attack.Generation,
+			Reason:             "NotCancelled",
+		}
+		if stopReason == stopReasonCancelled {
+			if alive > 0 {
+				cancelled.Reason = "Cancelling"
+				cancelled.Message = fmt.Sprintf("Waiting for %d attack pods to stop", alive)
+			} else {
+				cancelled.Status = metaV1.ConditionTrue
+				cancelled.Reason = "Cancelled"
+				cancelled.Message = fmt.Sprintf("Attack was cancelled by %s annotation", vegetaV2.CancelAnnotation)
+			}
+		}
+		vegetaV2.SetCondition(&status.Conditions, cancelled)
+	}
+	if vegetaV2.FindCondition(attack.Status.Conditions, vegetaV2.AttackCleanedUp) != nil {
+		// The job has been created again for the changed spec
+		vegetaV2.SetCondition(&status.Conditions, vegetaV2.Condition{
+			Type:               vegetaV2.AttackCleanedUp,
+			Status:             metaV1.ConditionFalse,
+			ObservedGeneration: attack.Generation,
+			Reason:             "JobRecreated",
+		})
+	}
+	for _, condition := range conditions {
+		vegetaV2.SetCondition(&status.Conditions, condition)
+	}
+	if collected > 0 || len(truncated) > 0 {
+		resultsCollected := buildResultsCollectedCondition(attack, truncated)
+		previous := vegetaV2.FindCondition(attack.Status.Conditions, vegetaV2.AttackResultsCollected)
+		if resultsCollected.Status == metaV1.ConditionFalse && (previous == nil || previous.Status != metaV1.ConditionFalse) {
+			r.Recorder.Eventf(attack, coreV1.EventTypeWarning, resultsCollected.Reason, "%s", resultsCollected.Message)
+		}
+		vegetaV2.SetCondition(&status.Conditions, resultsCollected)
+	}
+
+	if probed {
+		optionsSupported := vegetaV2.Condition{
+			Type:               vegetaV2.AttackOptionsSupported,
+			Status:             metaV1.ConditionTrue,
+			ObservedGeneration: attack.Generation,
+			Reason:             "OptionsSupported",
+		}
+		if len(unsupported) > 0 {
+			optionsSupported.Status = metaV1.ConditionFalse
+			optionsSupported.Reason = "UnsupportedOptions"
+			// All pods run the same image, so that they report the same flags
+			optionsSupported.Message = fmt.Sprintf("vegeta image %q does not support the options: %s", getVegetaImage(job), unsupported[0])
+			previous := vegetaV2.FindCondition(attack.Status.Conditions, vegetaV2.AttackOptionsSupported)
+			if previous == nil || previous.Status != metaV1.ConditionFalse {
+				r.Recorder.Eventf(attack, coreV1.EventTypeWarning, optionsSupported.Reason, "%s", optionsSupported.Message)
+			}
+		}
+		vegetaV2.SetCondition(&status.Conditions, optionsSupported)
+	}
+
+	if attack.Spec.Thresholds != nil {
+		passed := buildPassedCondition(attack, status.Phase, summaryMetrics)
+		previous := vegetaV2.FindCondition(attack.Status.Conditions, vegetaV2.AttackPassed)
+		if passed.Status == metaV1.ConditionFalse && (previous == nil || previous.Status != metaV1.ConditionFalse) {
+			r.Recorder.Eventf(attack, coreV1.EventTypeWarning, passed.Reason, "Attack did not pass: %s", passed.Message)
+		}
+		vegetaV2.SetCondition(&status.Conditions, passed)
+	}
+
+	if equality.Semantic.DeepEqual(&attack.Status, status) {
+		return nil
+	}
+
+	if attack.Status.Phase != status.Phase {
+		r.Recorder.Eventf(attack, coreV1.EventTypeNormal, string(status.Phase), "Attack is %s", strings.ToLower(string(status.Phase)))
+	}
+	attack.Status = *status
+	return r.Status().Update(ctx, attack)
+}
+
+// findJobCondition returns the condition of the given type only if it is True
+func findJobCondition(job *batchV1.Job, conditionType batchV1.JobConditionType) *batchV1.JobCondition {
+	for i := range job.Status.Conditions {
+		condition := &job.Status.Conditions[i]
+		if condition.Type == conditionType && condition.Status == v1.ConditionTrue {
+			return condition
+		}
+	}
+	return nil
+}
+
+// getVegetaImage returns the image which the job runs the attack with
+func getVegetaImage(job *batchV1.Job) string {
+	for _, container := range job.Spec.Template.Spec.Containers {
+		if container.Name == "vegeta" {
+			return container.Image
+		}
+	}
+	return ""
+}
+
+func isContainerRunning(pod *v1.Pod, name string) bool {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name == name {
+			return containerStatus.State.Running != nil
+		}
+	}
+	return false
+}
+
+func isContainerTerminated(pod *v1.Pod, name string) bool {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name == name {
+			return containerStatus.State.Terminated != nil
+		}
+	}
+	return false
+}
+
+func isJobFinished(job *batchV1.Job) bool {
+	return findJobCondition(job, batchV1.JobComplete) != nil || findJobCondition(job, batchV1.JobFailed) != nil
+}
+
+// computeHash returns a stable hash of the JSON representation of the object
+func computeHash(object interface{}) (string, error) {
+	encoded, err := computeJSON(object)
+	if err != nil {
+		return "", err
+	}
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(encoded))
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32())), nil
+}
+
+// computeConfigMapHash hashes binary data only if any, so that the hashes of existing config maps are kept
+func computeConfigMapHash(configMap *v1.ConfigMap) (string, error) {
+	if len(configMap.BinaryData) == 0 {
+		return computeHash(configMap.Data)
+	}
+	return computeHash([]interface{}{configMap.Data, configMap.BinaryData})
+}
+
+func computeJSON(object interface{}) (string, error) {
+	b, err := json.Marshal(object)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (r *AttackReconciler) buildScenarioConfigMap(attack *vegetaV2.Attack) *v1.ConfigMap {
 	return &v1.ConfigMap{
 		ObjectMeta: metaV1.ObjectMeta{
 			Name:      attack.Name + "-scenario",
@@ -129,7 +867,7 @@ func (r *AttackReconciler) buildScenarioConfigMap(attack *vegetaV1.Attack) *v1.C
 	}
 }
 
-func (r *AttackReconciler) buildNSSwitchConfigMap(attack *vegetaV1.Attack) *v1.ConfigMap {
+func (r *AttackReconciler) buildNSSwitchConfigMap(attack *vegetaV2.Attack) *v1.ConfigMap {
 	return &v1.ConfigMap{
 		ObjectMeta: metaV1.ObjectMeta{
 			Name:      attack.Name + "-nsswitch",
@@ -141,8 +879,7 @@ func (r *AttackReconciler) buildNSSwitchConfigMap(attack *vegetaV1.Attack) *v1.C
 	}
 }
 
-func (r *AttackReconciler) buildJob(attack *vegetaV1.Attack) *batchV1.Job {
-	fmt.Printf("%+v", attack.Spec)
+func (r *AttackReconciler) buildJob(attack *vegetaV2.Attack) (*batchV1.Job, error) {
 	appLabel := attack.Name + "-attack"
 
 	labels := map[string]string{
@@ -151,29 +888,31 @@ func (r *AttackReconciler) buildJob(attack *vegetaV1.Attack) *batchV1.Job {
 	for k, v := range attack.Spec.Template.ObjectMeta.Labels {
 		labels[k] = v
 	}
+	labels[attackLabel] = attack.Name
 	attack.Spec.Template.ObjectMeta.Labels = labels
 
-	var options []string
-	if attack.Spec.Option.Duration != "" {
-		options = append(options, fmt.Sprintf("-duration %s", attack.Spec.Option.Duration))
-	}
-	if attack.Spec.Option.Rate != 0 {
-		options = append(options, fmt.Sprintf("-rate %d", attack.Spec.Option.Rate))
+	annotations := map[string]string{
+		"kubectl.kubernetes.io/default-container": "vegeta",
 	}
-	if attack.Spec.Option.Connections != 0 {
-		options = append(options, fmt.Sprintf("-connections %d", attack.Spec.Option.Connections))
+	for k, v := range attack.Spec.Template.ObjectMeta.Annotations {
+		annotations[k] = v
 	}
-	if attack.Spec.Option.Timeout != "" {
-		options = append(options, fmt.Sprintf("-timeout %s", attack.Spec.Option.Timeout))
-	}
-	if attack.Spec.Option.Workers != 0 {
-		options = append(options, fmt.Sprintf("-workers %d", attack.Spec.Option.Workers))
-	}
-	if attack.Spec.Option.Format != "" {
-		options = append(options, fmt.Sprintf("-format %s", attack.Spec.Option.Format))
+	attack.Spec.Template.ObjectMeta.Annotations = annotations
+
+	podOption := buildPodOption(attack)
+	if attack.Spec.Option.RateMode == vegetaV2.TotalRateMode {
+		// The divided options depend on parallelism, so scaling replaces the job through the pod template hash
+		encoded, err := computeJSON(podOption)
+		if err != nil {
+			return nil, err
+		}
+		annotations[podOptionAnnotation] = encoded
 	}
-	if !attack.Spec.Option.Keepalive {
-		options = append(options, "-keepalive false")
+
+	var options []string
+	flags := buildAttackFlags(attack, podOption)
+	for _, flag := range flags {
+		options = append(options, flag.String())
 	}
 
 	var vegetaImage string
@@ -183,7 +922,102 @@ func (r *AttackReconciler) buildJob(attack *vegetaV1.Attack) *batchV1.Job {
 		vegetaImage = r.VegetaImage
 	}
 
-	return &batchV1.Job{
+	// The results are kept to write JSON report into termination message, and are also handed to results container
+	// through the file descriptor kept open across the steps, so that it reads them as a single stream.
+	// The reports generated by vegeta are handed to it through another pipe after the results.
+	// The descriptors are opened first, so that results container also finishes when the script fails early.
+	// On termination, such as by the stop annotation of the pod or the deadline, the running step is interrupted
+	// and the rest are skipped, so that the results until then are reported as usual.
+	script := []string{
+		"set -e",
+		"{ [ -p /var/run/vegeta/results.fifo ] || mkfifo /var/run/vegeta/results.fifo; } 2>/dev/null",
+		"{ [ -p /var/run/vegeta/artifacts.fifo ] || mkfifo /var/run/vegeta/artifacts.fifo; } 2>/dev/null",
+		"exec 3> /var/run/vegeta/results.fifo 4> /var/run/vegeta/artifacts.fifo",
+		"stopped=",
+		`trap 'stopped=1; pkill -INT -f "^vegeta attack" || true' TERM`,
+	}
+	stopCondition := fmt.Sprintf("grep -q '^%s=' %s/annotations 2>/dev/null", stopAnnotation, podInfoMountPath)
+	if deadline := buildDeadlineCondition(attack); deadline != "" {
+		stopCondition += " || " + deadline
+	}
+	script = append(script, fmt.Sprintf("( while sleep 1; do if %s; then kill -TERM $$; break; fi; done ) 3>&- 4>&- &", stopCondition))
+	probed := flags
+	if len(attack.Spec.Stages) > 0 {
+		// The name of each stage is given by -name, which old images of vegeta lack
+		probed = append(probed, attackFlag{name: "name"})
+	}
+	script = append(script, buildFlagProbe(probed)...)
+	targets := "/var/lib/vegeta/scenario"
+	if attack.Spec.ScenarioFrom != nil {
+		// vegeta needs line break, which may be missing in the referenced scenario
+		script = append(script, "{ cat /var/lib/vegeta/scenario; echo; } > /var/run/vegeta/scenario")
+		targets = "/var/run/vegeta/scenario"
+	}
+
+	script = append(script, buildStartBarrierScript(attack)...)
+
+	steps := buildAttackSteps(attack)
+	var results []string
+	stageResults := map[string][]string{}
+	for i, step := range steps {
+		var stepOptions []string
+		if step.stage != "" {
+			// The name is encoded in raw results to group them by stage
+			stepOptions = append(stepOptions, fmt.Sprintf("-name %s", step.stage))
+		}
+		if step.duration != "" {
+			stepOptions = append(stepOptions, fmt.Sprintf("-duration %s", step.duration))
+		}
+		if rate := formatPodRate(attack, step.rate); rate != "" {
+			stepOptions = append(stepOptions, fmt.Sprintf("-rate %s", rate))
+		}
+		stepOptions = append(stepOptions, options...)
+
+		result := fmt.Sprintf("/var/run/vegeta/results-%d.bin", i)
+		results = append(results, result)
+		stageResults[step.stage] = append(stageResults[step.stage], result)
+		// The step runs in background, since the trap runs only after the foreground command exits.
+		// wait returns early by the trap, and then waits for the interrupted step again.
+		script = append(
+			script,
+			fmt.Sprintf(
+				"if [ -z \"$stopped\" ]; then vegeta attack %s -targets %s | tee %s | vegeta encode -to csv | awk '%s' >&3 & wait $! || wait $!; else : > %s; fi",
+				strings.Join(stepOptions, " "),
+				targets,
+				result,
+				stripBodiesProgram,
+				result,
+			),
+		)
+	}
+	script = append(script, "exec 3>&-")
+
+	for i := range attack.Spec.Stages {
+		name := stageName(i)
+		if attack.Spec.Output == "text" {
+			script = append(script, fmt.Sprintf("echo '==> %s <=='", name))
+		}
+		script = append(script, fmt.Sprintf("vegeta report -type %s %s", attack.Spec.Output, strings.Join(stageResults[name], " ")))
+	}
+	if len(attack.Spec.Stages) > 0 && attack.Spec.Output == "text" {
+		script = append(script, "echo '==> total <=='")
+	}
+	script = append(
+		script,
+		fmt.Sprintf("vegeta report -type %s %s", attack.Spec.Output, strings.Join(results, " ")),
+		fmt.Sprintf("vegeta report -type json %s > /dev/termination-log", strings.Join(results, " ")),
+	)
+	script = append(script, buildArtifactScript(attack, results)...)
+
+	// vegeta waits for the requests in flight up to the timeout after it is interrupted, and then the reports are written
+	timeout := 30 * time.Second
+	if attack.Spec.Option.Timeout != nil && attack.Spec.Option.Timeout.Duration > 0 {
+		timeout = attack.Spec.Option.Timeout.Duration
+	}
+	terminationGracePeriodSeconds := int64((timeout + 30*time.Second) / time.Second)
+	affinity, topologySpreadConstraints := buildPlacement(attack, appLabel)
+
+	job := &batchV1.Job{
 		ObjectMeta: metaV1.ObjectMeta{
 			Name:      attack.Name + "-attack",
 			Namespace: attack.Namespace,
@@ -193,34 +1027,24 @@ func (r *AttackReconciler) buildJob(attack *vegetaV1.Attack) *batchV1.Job {
 			Template: v1.PodTemplateSpec{
 				ObjectMeta: attack.Spec.Template.ObjectMeta,
 				Spec: v1.PodSpec{
-					Affinity: &v1.Affinity{
-						PodAntiAffinity: &v1.PodAntiAffinity{
-							PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{
-								{
-									Weight: 100,
-									PodAffinityTerm: v1.PodAffinityTerm{
-										LabelSelector: &metaV1.LabelSelector{
-											MatchLabels: map[string]string{
-												"app": appLabel,
-											},
-										},
-										TopologyKey: "kubernetes.io/hostname",
-									},
-								},
-							},
-						},
-					},
-					HostAliases: attack.Spec.Template.Spec.HostAliases,
+					Affinity:                  affinity,
+					HostAliases:               attack.Spec.Template.Spec.HostAliases,
+					NodeSelector:              attack.Spec.Template.Spec.NodeSelector,
+					Tolerations:               attack.Spec.Template.Spec.Tolerations,
+					TopologySpreadConstraints: topologySpreadConstraints,
+					PriorityClassName:         attack.Spec.Template.Spec.PriorityClassName,
+					ServiceAccountName:        attack.Spec.Template.Spec.ServiceAccountName,
+					SecurityContext:           attack.Spec.Template.Spec.SecurityContext,
+					ImagePullSecrets:          attack.Spec.Template.Spec.ImagePullSecrets,
+					DNSPolicy:                 attack.Spec.Template.Spec.DNSPolicy,
+					DNSConfig:                 attack.Spec.Template.Spec.DNSConfig,
 					Containers: []v1.Container{
 						{
-							Name:    "vegeta",
-							Image:   vegetaImage,
-							Command: []string{"sh"},
-							Args: []string{"-c", fmt.Sprintf(
-								"vegeta attack %s -targets /var/lib/vegeta/scenario | vegeta report -type %s",
-								strings.Join(options, " "),
-								attack.Spec.Output,
-							)},
+							Name:            "vegeta",
+							Image:           vegetaImage,
+							Command:         []string{"sh"},
+							Args:            []string{"-c", strings.Join(script, "\n")},
+							Env:             buildHeaderEnv(attack),
 							ImagePullPolicy: v1.PullIfNotPresent,
 							Resources:       attack.Spec.AttackContainerSpec.Resources,
 							VolumeMounts: []v1.VolumeMount{
@@ -228,44 +1052,139 @@ func (r *AttackReconciler) buildJob(attack *vegetaV1.Attack) *batchV1.Job {
 									Name:      "scenario",
 									MountPath: "/var/lib/vegeta",
 								},
+								{
+									Name:      "results",
+									MountPath: "/var/run/vegeta",
+								},
 								{
 									Name:      "nsswitch",
 									MountPath: "/etc/nsswitch.conf",
 									SubPath:   "nsswitch.conf",
 								},
+								{
+									Name:      "podinfo",
+									MountPath: podInfoMountPath,
+									ReadOnly:  true,
+								},
+							},
+						},
+						{
+							// Raw results are streamed to the log of this container as CSV, so that the controller can merge
+							// the results of all pods exactly.
+							// The number of results is written to the termination message to detect those lost by log rotation.
+							// The reports generated by vegeta container follow the results.
+							Name:    resultsContainerName,
+							Image:   vegetaImage,
+							Command: []string{"sh"},
+							Args: []string{"-c",
+								"{ [ -p /var/run/vegeta/results.fifo ] || mkfifo /var/run/vegeta/results.fifo; " +
+									"[ -p /var/run/vegeta/artifacts.fifo ] || mkfifo /var/run/vegeta/artifacts.fifo; } 2>/dev/null; " +
+									// Results are streamed until vegeta container closes the pipe even on termination
+									"trap '' TERM; " +
+									// The pipes are opened in the same order as vegeta container, not to block each other
+									"exec 3< /var/run/vegeta/results.fifo 4< /var/run/vegeta/artifacts.fifo; " +
+									fmt.Sprintf("awk '%s' <&3; ", countResultsProgram) +
+									"cat <&4",
+							},
+							ImagePullPolicy: v1.PullIfNotPresent,
+							VolumeMounts: []v1.VolumeMount{
+								{
+									Name:      "results",
+									MountPath: "/var/run/vegeta",
+								},
 							},
 						},
 					},
 					Volumes: []v1.Volume{
 						{
-							Name: "scenario",
+							Name:         "scenario",
+							VolumeSource: buildScenarioVolumeSource(attack),
+						},
+						{
+							Name: "nsswitch",
 							VolumeSource: v1.VolumeSource{
 								ConfigMap: &v1.ConfigMapVolumeSource{
 									LocalObjectReference: v1.LocalObjectReference{
-										Name: attack.Name + "-scenario",
+										Name: attack.Name + "-nsswitch",
 									},
 								},
 							},
 						},
 						{
-							Name: "nsswitch",
+							Name: "results",
 							VolumeSource: v1.VolumeSource{
-								ConfigMap: &v1.ConfigMapVolumeSource{
-									LocalObjectReference: v1.LocalObjectReference{
-										Name: attack.Name + "-nsswitch",
+								EmptyDir: &v1.EmptyDirVolumeSource{},
+							},
+						},
+						{
+							Name: "podinfo",
+							VolumeSource: v1.VolumeSource{
+								DownwardAPI: &v1.DownwardAPIVolumeSource{
+									Items: []v1.DownwardAPIVolumeFile{
+										{
+											Path: "annotations",
+											FieldRef: &v1.ObjectFieldSelector{
+												FieldPath: "metadata.annotations",
+											},
+										},
+										{
+											Path: "labels",
+											FieldRef: &v1.ObjectFieldSelector{
+												FieldPath: "metadata.labels",
+											},
+										},
 									},
 								},
 							},
 						},
 					},
-					RestartPolicy: v1.RestartPolicyNever,
+					RestartPolicy:                 v1.RestartPolicyNever,
+					TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
 				},
 			},
 		},
 	}
+
+	if bodiesVolumeSource := buildBodiesVolumeSource(attack); bodiesVolumeSource != nil {
+		podSpec := &job.Spec.Template.Spec
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, v1.VolumeMount{
+			Name:      "bodies",
+			MountPath: vegetaV2.BodyMountPath,
+			ReadOnly:  true,
+		})
+		podSpec.Volumes = append(podSpec.Volumes, v1.Volume{
+			Name:         "bodies",
+			VolumeSource: *bodiesVolumeSource,
+		})
+	}
+	if startVolumeSource := buildStartBarrierVolumeSource(attack); startVolumeSource != nil {
+		podSpec := &job.Spec.Template.Spec
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, v1.VolumeMount{
+			Name:      "start",
+			MountPath: startBarrierMountPath,
+			ReadOnly:  true,
+		})
+		podSpec.Volumes = append(podSpec.Volumes, v1.Volume{
+			Name:         "start",
+			VolumeSource: *startVolumeSource,
+		})
+	}
+	if tlsVolumeSource := buildTLSVolumeSource(attack); tlsVolumeSource != nil {
+		podSpec := &job.Spec.Template.Spec
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, v1.VolumeMount{
+			Name:      "tls",
+			MountPath: tlsMountPath,
+			ReadOnly:  true,
+		})
+		podSpec.Volumes = append(podSpec.Volumes, v1.Volume{
+			Name:         "tls",
+			VolumeSource: *tlsVolumeSource,
+		})
+	}
+	return job, nil
 }
 
-func (r *AttackReconciler) cleanupOwnedResources(ctx context.Context, attack *vegetaV1.Attack) error {
+func (r *AttackReconciler) cleanupOwnedResources(ctx context.Context, attack *vegetaV2.Attack) error {
 	var jobs batchV1.JobList
 	if err := r.List(
 		ctx,
@@ -302,7 +1221,21 @@ func (r *AttackReconciler) cleanupOwnedResources(ctx context.Context, attack *ve
 	for _, configMap := range configMaps.Items {
 		configMap := configMap
 
-		if configMap.Name == attack.Name+"-scenario" || configMap.Name == attack.Name+"-nsswitch" {
+		if configMap.Name == attack.Name+"-nsswitch" {
+			continue
+		}
+		// The scenario config map is not used when scenarioFrom is specified
+		if configMap.Name == attack.Name+"-scenario" && attack.Spec.ScenarioFrom == nil {
+			continue
+		}
+		// The bodies config map is used only for inline bodies
+		if configMap.Name == attack.Name+"-bodies" && hasInlineBodies(attack) {
+			continue
+		}
+		if isReportConfigMap(attack, configMap.Name) && len(attack.Spec.Reports) > 0 {
+			continue
+		}
+		if configMap.Name == attack.Name+"-start" && attack.Spec.StartBarrier != nil {
 			continue
 		}
 
@@ -316,6 +1249,22 @@ func (r *AttackReconciler) cleanupOwnedResources(ctx context.Context, attack *ve
 }
 
 func (r *AttackReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.results == nil {
+		r.results = newResultStore()
+	}
+	if r.live == nil {
+		r.live = newLiveStreamer(r.Log)
+	}
+	if r.aborts == nil {
+		r.aborts = newAbortTracker()
+	}
+	if r.FinalizeTimeout == 0 {
+		r.FinalizeTimeout = defaultFinalizeTimeout
+	}
+	if r.APIReader == nil {
+		r.APIReader = mgr.GetAPIReader()
+	}
+
 	if err := mgr.GetFieldIndexer().IndexField(&batchV1.Job{}, ownerKey, func(rawObj runtime.Object) []string {
 		job := rawObj.(*batchV1.Job)
 		owner := metaV1.GetControllerOf(job)
@@ -346,9 +1295,64 @@ func (r *AttackReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	if err := mgr.GetFieldIndexer().IndexField(&vegetaV2.Attack{}, referencedConfigMapKey, indexReferencedConfigMaps); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(&vegetaV2.Attack{}, referencedSecretKey, indexReferencedSecrets); err != nil {
+		return err
+	}
+
+	// Only the metadata of Secrets is watched to notice their changes, whose data is read by APIReader
+	metadataClient, err := metadata.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	secrets := metadatainformer.NewFilteredMetadataInformer(
+		metadataClient,
+		v1.SchemeGroupVersion.WithResource("secrets"),
+		metaV1.NamespaceAll,
+		0,
+		toolsCache.Indexers{},
+		nil,
+	).Informer()
+	if err := mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+		secrets.Run(stop)
+		return nil
+	})); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&vegetaV1.Attack{}).
+		For(&vegetaV2.Attack{}).
 		Owns(&batchV1.Job{}).
 		Owns(&v1.ConfigMap{}).
+		Watches(
+			&source.Kind{Type: &v1.ConfigMap{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: r.referencingAttacks(referencedConfigMapKey)},
+		).
+		Watches(
+			&source.Informer{Informer: secrets},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: r.referencingAttacks(referencedSecretKey)},
+		).
+		Watches(
+			&source.Kind{Type: &v1.Pod{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.podToAttack)},
+		).
 		Complete(r)
 }
+
+func (r *AttackReconciler) podToAttack(object handler.MapObject) []reconcile.Request {
+	name, ok := object.Meta.GetLabels()[attackLabel]
+	if !ok {
+		return nil
+	}
+
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Name:      name,
+				Namespace: object.Meta.GetNamespace(),
+			},
+		},
+	}
+}
@@ -0,0 +1,749 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	batchV1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	k8sFake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// newAttackReconciler returns the reconciler of the fake clients initialized with the objects
+func newAttackReconciler(objs ...runtime.Object) *AttackReconciler {
+	c := newFakeClient(objs...)
+	return &AttackReconciler{
+		Client:          c,
+		Log:             log.NullLogger{},
+		Scheme:          scheme.Scheme,
+		Recorder:        record.NewFakeRecorder(1000),
+		Clientset:       k8sFake.NewSimpleClientset(),
+		FinalizeTimeout: defaultFinalizeTimeout,
+		APIReader:       c,
+		results:         newResultStore(),
+		live:            newLiveStreamer(log.NullLogger{}),
+		aborts:          newAbortTracker(),
+	}
+}
+
+func newTestAttack() *vegetaV2.Attack {
+	return &vegetaV2.Attack{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:       "sample",
+			Namespace:  "default",
+			UID:        "attack",
+			Generation: 1,
+		},
+		Spec: vegetaV2.AttackSpec{
+			Parallelism: 2,
+			Scenario:    "GET http://example.com/",
+		},
+	}
+}
+
+func reconcileAttack(t *testing.T, r *AttackReconciler, attack *vegetaV2.Attack) ctrl.Result {
+	t.Helper()
+	result, err := r.Reconcile(ctrl.Request{NamespacedName: client.ObjectKey{Name: attack.Name, Namespace: attack.Namespace}})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	return result
+}
+
+func getAttack(t *testing.T, r *AttackReconciler, attack *vegetaV2.Attack) *vegetaV2.Attack {
+	t.Helper()
+	var current vegetaV2.Attack
+	if err := r.Get(context.Background(), client.ObjectKey{Name: attack.Name, Namespace: attack.Namespace}, &current); err != nil {
+		t.Fatalf("unable to get attack: %v", err)
+	}
+	return &current
+}
+
+// updateAttack changes the spec of the attack, whose generation is incremented as the API server does
+func updateAttack(t *testing.T, r *AttackReconciler, attack *vegetaV2.Attack, update func(attack *vegetaV2.Attack)) {
+	t.Helper()
+	current := getAttack(t, r, attack)
+	update(current)
+	current.Generation++
+	if err := r.Update(context.Background(), current); err != nil {
+		t.Fatalf("unable to update attack: %v", err)
+	}
+}
+
+// getJob returns the job of the attack, or nil when it does not exist
+func getJob(t *testing.T, r *AttackReconciler, attack *vegetaV2.Attack) *batchV1.Job {
+	t.Helper()
+	var job batchV1.Job
+	if err := r.Get(context.Background(), client.ObjectKey{Name: attack.Name + "-attack", Namespace: attack.Namespace}, &job); errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		t.Fatalf("unable to get job: %v", err)
+	}
+	return &job
+}
+
+// startJob reconciles the attack to create its job, and makes the job running with its pods as the job controller does,
+// since the fake client does not assign UIDs
+func startJob(t *testing.T, r *AttackReconciler, attack *vegetaV2.Attack) *batchV1.Job {
+	t.Helper()
+	reconcileAttack(t, r, attack)
+	job := getJob(t, r, attack)
+	if job == nil {
+		t.Fatalf("Reconcile() did not create the job")
+	}
+	job.UID = "job"
+	job.CreationTimestamp = metaV1.Now()
+	job.Status.Active = *job.Spec.Parallelism
+	job.Status.StartTime = &job.CreationTimestamp
+	if err := r.Update(context.Background(), job); err != nil {
+		t.Fatalf("unable to update job: %v", err)
+	}
+	for i := int32(0); i < *job.Spec.Parallelism; i++ {
+		name := fmt.Sprintf("%s-%d", job.Name, i)
+		pod := &v1.Pod{
+			ObjectMeta: metaV1.ObjectMeta{
+				Name:      name,
+				Namespace: job.Namespace,
+				UID:       types.UID(name),
+				Labels:    map[string]string{"controller-uid": string(job.UID)},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		}
+		if err := r.Create(context.Background(), pod); err != nil {
+			t.Fatalf("unable to create pod: %v", err)
+		}
+	}
+	return job
+}
+
+// finishJob completes the job and its pods as the job controller does
+func finishJob(t *testing.T, r *AttackReconciler, job *batchV1.Job) {
+	t.Helper()
+	job.Status.Active = 0
+	job.Status.Succeeded = *job.Spec.Parallelism
+	job.Status.CompletionTime = &metaV1.Time{Time: time.Now()}
+	job.Status.Conditions = append(job.Status.Conditions, batchV1.JobCondition{
+		Type:               batchV1.JobComplete,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: *job.Status.CompletionTime,
+	})
+	if err := r.Update(context.Background(), job); err != nil {
+		t.Fatalf("unable to update job: %v", err)
+	}
+	var pods v1.PodList
+	if err := r.List(context.Background(), &pods, client.MatchingLabels{"controller-uid": string(job.UID)}); err != nil {
+		t.Fatal(err)
+	}
+	for i := range pods.Items {
+		pods.Items[i].Status.Phase = v1.PodSucceeded
+		if err := r.Update(context.Background(), &pods.Items[i]); err != nil {
+			t.Fatalf("unable to update pod: %v", err)
+		}
+	}
+}
+
+func TestAttackReconcileSpecChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   vegetaV2.ReplacePolicy
+		finished bool
+		// wantReplaced is whether the job is replaced for the changed scenario
+		wantReplaced bool
+	}{
+		{
+			name:         "finished job",
+			finished:     true,
+			wantReplaced: true,
+		},
+		{
+			name: "running job refused by Forbid",
+		},
+		{
+			name:         "running job restarted by Restart",
+			policy:       vegetaV2.RestartReplacePolicy,
+			wantReplaced: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attack := newTestAttack()
+			attack.Spec.ReplacePolicy = tt.policy
+			r := newAttackReconciler(attack)
+			job := startJob(t, r, attack)
+			if tt.finished {
+				finishJob(t, r, job)
+			}
+			hash := job.Annotations[specHashAnnotation]
+
+			updateAttack(t, r, attack, func(attack *vegetaV2.Attack) {
+				attack.Spec.Scenario = "GET http://example.com/v2"
+			})
+			reconcileAttack(t, r, attack)
+			if replaced := getJob(t, r, attack) == nil; replaced != tt.wantReplaced {
+				t.Fatalf("Reconcile() deleted the job = %v, want %v", replaced, tt.wantReplaced)
+			}
+			if !tt.wantReplaced {
+				condition := vegetaV2.FindCondition(getAttack(t, r, attack).Status.Conditions, vegetaV2.AttackSpecApplied)
+				if condition == nil || condition.Status != metaV1.ConditionFalse || condition.Reason != "ReplaceRefused" {
+					t.Errorf("Reconcile() SpecApplied condition = %+v, want False by ReplaceRefused", condition)
+				}
+				return
+			}
+
+			// The deletion of the job triggers the next reconciliation, which creates the job of the changed spec
+			reconcileAttack(t, r, attack)
+			replaced := getJob(t, r, attack)
+			if replaced == nil {
+				t.Fatalf("Reconcile() did not create the job again")
+			}
+			if replaced.Annotations[specHashAnnotation] == hash {
+				t.Errorf("Reconcile() created the job of the same spec hash %s", hash)
+			}
+		})
+	}
+}
+
+func TestAttackReconcileScale(t *testing.T) {
+	attack := newTestAttack()
+	r := newAttackReconciler(attack)
+	job := startJob(t, r, attack)
+
+	updateAttack(t, r, attack, func(attack *vegetaV2.Attack) {
+		attack.Spec.Parallelism = 3
+	})
+	reconcileAttack(t, r, attack)
+	scaled := getJob(t, r, attack)
+	if scaled == nil || scaled.UID != job.UID {
+		t.Fatalf("Reconcile() replaced the job = %+v, want it scaled", scaled)
+	}
+	if *scaled.Spec.Parallelism != 3 {
+		t.Errorf("Reconcile() parallelism = %d, want 3", *scaled.Spec.Parallelism)
+	}
+}
+
+// listStoppedPods returns the names of the pods of the job which have been requested to stop for the reason
+func listStoppedPods(t *testing.T, r *AttackReconciler, job *batchV1.Job, reason string) []string {
+	t.Helper()
+	var pods v1.PodList
+	if err := r.List(context.Background(), &pods, client.MatchingLabels{"controller-uid": string(job.UID)}); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, pod := range pods.Items {
+		if pod.Annotations[stopAnnotation] == reason {
+			names = append(names, pod.Name)
+		}
+	}
+	return names
+}
+
+func TestAttackReconcileSuspend(t *testing.T) {
+	attack := newTestAttack()
+	r := newAttackReconciler(attack)
+	job := startJob(t, r, attack)
+
+	updateAttack(t, r, attack, func(attack *vegetaV2.Attack) {
+		attack.Spec.Suspend = true
+	})
+	reconcileAttack(t, r, attack)
+	stopped := getJob(t, r, attack)
+	if stopped == nil || stopped.UID != job.UID || getStopReason(stopped) != stopReasonSuspended {
+		t.Fatalf("Reconcile() job = %+v, want it stopped by suspension", stopped)
+	}
+	if pods := listStoppedPods(t, r, stopped, stopReasonSuspended); len(pods) != 2 {
+		t.Errorf("Reconcile() stopped pods %q, want both", pods)
+	}
+	current := getAttack(t, r, attack)
+	if current.Status.Phase != vegetaV2.AttackSuspended {
+		t.Errorf("Reconcile() phase = %s, want %s", current.Status.Phase, vegetaV2.AttackSuspended)
+	}
+	if condition := vegetaV2.FindCondition(current.Status.Conditions, vegetaV2.AttackSuspension); condition == nil || condition.Reason != "Suspending" {
+		t.Errorf("Reconcile() Suspended condition = %+v, want Suspending while the pods stop", condition)
+	}
+
+	finishJob(t, r, stopped)
+	reconcileAttack(t, r, attack)
+	current = getAttack(t, r, attack)
+	if condition := vegetaV2.FindCondition(current.Status.Conditions, vegetaV2.AttackSuspension); condition == nil || condition.Status != metaV1.ConditionTrue {
+		t.Errorf("Reconcile() Suspended condition = %+v, want True after the pods stopped", condition)
+	}
+
+	updateAttack(t, r, attack, func(attack *vegetaV2.Attack) {
+		attack.Spec.Suspend = false
+	})
+	reconcileAttack(t, r, attack)
+	if job := getJob(t, r, attack); job != nil {
+		t.Fatalf("Reconcile() kept the suspended job = %+v, want it deleted to resume", job)
+	}
+	reconcileAttack(t, r, attack)
+	if resumed := getJob(t, r, attack); resumed == nil || isJobStopped(resumed) {
+		t.Errorf("Reconcile() job = %+v, want it created again to resume", resumed)
+	}
+}
+
+func TestAttackReconcileSuspendBeforeStart(t *testing.T) {
+	attack := newTestAttack()
+	attack.Spec.Suspend = true
+	r := newAttackReconciler(attack)
+
+	reconcileAttack(t, r, attack)
+	if job := getJob(t, r, attack); job != nil {
+		t.Fatalf("Reconcile() created the job = %+v of the suspended attack", job)
+	}
+	if phase := getAttack(t, r, attack).Status.Phase; phase != vegetaV2.AttackSuspended {
+		t.Errorf("Reconcile() phase = %s, want %s", phase, vegetaV2.AttackSuspended)
+	}
+}
+
+func TestAttackReconcileCancel(t *testing.T) {
+	tests := []struct {
+		name string
+		// initial is the cancel annotation which the attack is created with
+		initial string
+		// cancel is the cancel annotation set after the job is created
+		cancel        string
+		wantCancelled bool
+	}{
+		{
+			name:    "annotated before the job is created",
+			initial: "2020-01-01T00:00:00Z",
+		},
+		{
+			name:          "annotated while running",
+			cancel:        "2020-01-01T00:00:00Z",
+			wantCancelled: true,
+		},
+		{
+			name:          "annotated again while running",
+			initial:       "2020-01-01T00:00:00Z",
+			cancel:        "2020-01-02T00:00:00Z",
+			wantCancelled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attack := newTestAttack()
+			if tt.initial != "" {
+				attack.Annotations = map[string]string{vegetaV2.CancelAnnotation: tt.initial}
+			}
+			r := newAttackReconciler(attack)
+			job := startJob(t, r, attack)
+			if tt.cancel != "" {
+				// Annotations do not change the generation
+				current := getAttack(t, r, attack)
+				if current.Annotations == nil {
+					current.Annotations = map[string]string{}
+				}
+				current.Annotations[vegetaV2.CancelAnnotation] = tt.cancel
+				if err := r.Update(context.Background(), current); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			reconcileAttack(t, r, attack)
+			current := getJob(t, r, attack)
+			if current == nil || current.UID != job.UID {
+				t.Fatalf("Reconcile() job = %+v, want it kept", current)
+			}
+			if cancelled := getStopReason(current) == stopReasonCancelled; cancelled != tt.wantCancelled {
+				t.Fatalf("Reconcile() cancelled the job = %v, want %v", cancelled, tt.wantCancelled)
+			}
+			if !tt.wantCancelled {
+				return
+			}
+			if pods := listStoppedPods(t, r, current, stopReasonCancelled); len(pods) != 2 {
+				t.Errorf("Reconcile() stopped pods %q, want both", pods)
+			}
+
+			finishJob(t, r, current)
+			reconcileAttack(t, r, attack)
+			status := getAttack(t, r, attack).Status
+			if status.Phase != vegetaV2.AttackCancelled {
+				t.Errorf("Reconcile() phase = %s, want %s", status.Phase, vegetaV2.AttackCancelled)
+			}
+			if condition := vegetaV2.FindCondition(status.Conditions, vegetaV2.AttackCancellation); condition == nil || condition.Status != metaV1.ConditionTrue {
+				t.Errorf("Reconcile() Cancelled condition = %+v, want True", condition)
+			}
+		})
+	}
+}
+
+func TestAttackReconcileTTL(t *testing.T) {
+	seconds := func(n int32) *int32 {
+		return &n
+	}
+
+	tests := []struct {
+		name   string
+		ttl    *int32
+		policy vegetaV2.CleanupPolicy
+		// defaultTTL is DefaultTTLSecondsAfterFinished of the controller
+		defaultTTL    *int32
+		wantJob       bool
+		wantAttack    bool
+		wantRequeue   bool
+		wantCleanedUp bool
+	}{
+		{
+			name:       "no TTL",
+			wantJob:    true,
+			wantAttack: true,
+		},
+		{
+			name:        "TTL not expired",
+			ttl:         seconds(3600),
+			wantJob:     true,
+			wantAttack:  true,
+			wantRequeue: true,
+		},
+		{
+			name:          "TTL expired",
+			ttl:           seconds(0),
+			wantAttack:    true,
+			wantCleanedUp: true,
+		},
+		{
+			name:          "default TTL expired",
+			defaultTTL:    seconds(0),
+			wantAttack:    true,
+			wantCleanedUp: true,
+		},
+		{
+			name:   "TTL expired with Attack policy",
+			ttl:    seconds(0),
+			policy: vegetaV2.AttackCleanupPolicy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attack := newTestAttack()
+			attack.Spec.TTLSecondsAfterFinished = tt.ttl
+			attack.Spec.CleanupPolicy = tt.policy
+			r := newAttackReconciler(attack)
+			r.DefaultTTLSecondsAfterFinished = tt.defaultTTL
+			job := startJob(t, r, attack)
+			finishJob(t, r, job)
+
+			// The status is updated with the completion before the TTL is evaluated
+			result := reconcileAttack(t, r, attack)
+			if requeued := result.RequeueAfter > 0; requeued != tt.wantRequeue {
+				t.Errorf("Reconcile() requeued after %s, want requeued %v", result.RequeueAfter, tt.wantRequeue)
+			}
+			var current vegetaV2.Attack
+			err := r.Get(context.Background(), client.ObjectKey{Name: attack.Name, Namespace: attack.Namespace}, &current)
+			if exists := err == nil; exists != tt.wantAttack {
+				t.Fatalf("Reconcile() kept the attack = %v, want %v", exists, tt.wantAttack)
+			}
+			if !tt.wantAttack {
+				// The job is deleted by the garbage collector along with the attack, which the fake client does not run
+				return
+			}
+			if exists := getJob(t, r, attack) != nil; exists != tt.wantJob {
+				t.Errorf("Reconcile() kept the job = %v, want %v", exists, tt.wantJob)
+			}
+			if cleanedUp := isAttackCleanedUp(&current); cleanedUp != tt.wantCleanedUp {
+				t.Errorf("Reconcile() cleaned up = %v, want %v", cleanedUp, tt.wantCleanedUp)
+			}
+			if !tt.wantCleanedUp {
+				return
+			}
+
+			// The job is not created again until the spec changes
+			reconcileAttack(t, r, attack)
+			if job := getJob(t, r, attack); job != nil {
+				t.Fatalf("Reconcile() created the job = %+v again after the cleanup", job)
+			}
+			updateAttack(t, r, attack, func(attack *vegetaV2.Attack) {
+				attack.Spec.Scenario = "GET http://example.com/v2"
+			})
+			reconcileAttack(t, r, attack)
+			if job := getJob(t, r, attack); job == nil {
+				t.Fatalf("Reconcile() did not create the job for the changed spec")
+			}
+			condition := vegetaV2.FindCondition(getAttack(t, r, attack).Status.Conditions, vegetaV2.AttackCleanedUp)
+			if condition == nil || condition.Status != metaV1.ConditionFalse || condition.Reason != "JobRecreated" {
+				t.Errorf("Reconcile() CleanedUp condition = %+v, want False by JobRecreated", condition)
+			}
+		})
+	}
+}
+
+// deleteAttack requests the deletion of the attack, which is kept by the finalizer as the API server does
+func deleteAttack(t *testing.T, r *AttackReconciler, attack *vegetaV2.Attack, at time.Time) {
+	t.Helper()
+	current := getAttack(t, r, attack)
+	if !hasFinalizer(current, resultsFinalizer) {
+		t.Fatalf("Reconcile() did not add the finalizer %s", resultsFinalizer)
+	}
+	deletionTimestamp := metaV1.NewTime(at)
+	current.DeletionTimestamp = &deletionTimestamp
+	if err := r.Update(context.Background(), current); err != nil {
+		t.Fatalf("unable to delete attack: %v", err)
+	}
+}
+
+func TestAttackReconcileFinalizer(t *testing.T) {
+	attack := newTestAttack()
+	r := newAttackReconciler(attack)
+	job := startJob(t, r, attack)
+	deleteAttack(t, r, attack, time.Now())
+
+	result := reconcileAttack(t, r, attack)
+	if result.RequeueAfter <= 0 {
+		t.Errorf("Reconcile() requeued after %s, want requeued while the pods stop", result.RequeueAfter)
+	}
+	stopped := getJob(t, r, attack)
+	if getStopReason(stopped) != stopReasonDeleted {
+		t.Fatalf("Reconcile() job stop reason = %q, want %q", getStopReason(stopped), stopReasonDeleted)
+	}
+	if pods := listStoppedPods(t, r, stopped, stopReasonDeleted); len(pods) != 2 {
+		t.Errorf("Reconcile() stopped pods %q, want both", pods)
+	}
+	if !hasFinalizer(getAttack(t, r, attack), resultsFinalizer) {
+		t.Fatalf("Reconcile() released the finalizer while the pods stop")
+	}
+
+	finishJob(t, r, stopped)
+	// The results are read from the logs of results containers once they have terminated
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = io.WriteString(w, "1500000000000000000,200,1000000,10,20,,\n")
+	}))
+	defer server.Close()
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Clientset = clientset
+	var pods v1.PodList
+	if err := r.List(context.Background(), &pods, client.MatchingLabels{"controller-uid": string(job.UID)}); err != nil {
+		t.Fatal(err)
+	}
+	for i := range pods.Items {
+		pods.Items[i].Status.ContainerStatuses = []v1.ContainerStatus{
+			{Name: resultsContainerName, State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{}}},
+		}
+		if err := r.Update(context.Background(), &pods.Items[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reconcileAttack(t, r, attack)
+	if hasFinalizer(getAttack(t, r, attack), resultsFinalizer) {
+		t.Errorf("Reconcile() kept the finalizer after the pods stopped")
+	}
+	// The results are exported even without reports, since the status is deleted along with the attack
+	var report v1.ConfigMap
+	if err := r.Get(context.Background(), client.ObjectKey{Name: reportConfigMapName(attack, 0), Namespace: attack.Namespace}, &report); err != nil {
+		t.Fatalf("Reconcile() did not export the report: %v", err)
+	}
+	for _, ownerReference := range report.OwnerReferences {
+		if ownerReference.UID == attack.UID {
+			t.Errorf("Reconcile() did not release the report from the attack")
+		}
+	}
+	if !strings.Contains(report.Data["json.json"], `"requests":2`) {
+		t.Errorf("Reconcile() exported the report %q, want the results of both pods", report.Data)
+	}
+}
+
+func TestAttackReconcileFinalizeTimeout(t *testing.T) {
+	attack := newTestAttack()
+	r := newAttackReconciler(attack)
+	startJob(t, r, attack)
+	deleteAttack(t, r, attack, time.Now().Add(-r.FinalizeTimeout))
+
+	// The stuck pods never block the deletion
+	reconcileAttack(t, r, attack)
+	if hasFinalizer(getAttack(t, r, attack), resultsFinalizer) {
+		t.Errorf("Reconcile() kept the finalizer after FinalizeTimeout")
+	}
+}
+
+func TestAttackReconcileStartBarrier(t *testing.T) {
+	tests := []struct {
+		name string
+		// pending is the number of the pods which are not ready yet
+		pending    int
+		wantReason string
+	}{
+		{
+			name:       "all pods ready",
+			wantReason: startReasonAllPodsReady,
+		},
+		{
+			name:       "some pods pending",
+			pending:    1,
+			wantReason: startReasonTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attack := newTestAttack()
+			attack.Spec.StartBarrier = &vegetaV2.StartBarrier{
+				Timeout: &metaV1.Duration{Duration: time.Hour},
+				Delay:   &metaV1.Duration{Duration: time.Minute},
+			}
+			r := newAttackReconciler(attack)
+			job := startJob(t, r, attack)
+			var pods v1.PodList
+			if err := r.List(context.Background(), &pods, client.MatchingLabels{"controller-uid": string(job.UID)}); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < tt.pending; i++ {
+				pods.Items[i].Status.Phase = v1.PodPending
+				if err := r.Update(context.Background(), &pods.Items[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			getStart := func() *v1.ConfigMap {
+				var configMap v1.ConfigMap
+				if err := r.Get(context.Background(), client.ObjectKey{Name: attack.Name + "-start", Namespace: attack.Namespace}, &configMap); err != nil {
+					t.Fatalf("Reconcile() did not create the start config map: %v", err)
+				}
+				return &configMap
+			}
+
+			// startAt is the timeout at first, and is brought forward on the next reconciliation
+			reconcileAttack(t, r, attack)
+			start := getStart()
+			timeout := getJob(t, r, attack).CreationTimestamp.Add(time.Hour)
+			if start.Data[startJobKey] != string(job.UID) || start.Data[startReasonKey] != startReasonTimeout || start.Data[startAtKey] != strconv.FormatInt(timeout.Unix(), 10) {
+				t.Fatalf("Reconcile() start = %q, want startAt %d by %s", start.Data, timeout.Unix(), startReasonTimeout)
+			}
+
+			before := time.Now()
+			reconcileAttack(t, r, attack)
+			start = getStart()
+			if start.Data[startReasonKey] != tt.wantReason {
+				t.Fatalf("Reconcile() start reason = %q, want %q", start.Data[startReasonKey], tt.wantReason)
+			}
+			startAt, err := strconv.ParseInt(start.Data[startAtKey], 10, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantReason == startReasonAllPodsReady && (startAt < before.Add(time.Minute).Unix() || startAt > time.Now().Add(time.Minute+time.Second).Unix()) {
+				t.Errorf("Reconcile() startAt = %d, want the delay after now", startAt)
+			}
+			if status := getAttack(t, r, attack).Status; status.StartAt == nil || status.StartAt.Unix() != startAt {
+				t.Errorf("Reconcile() status.startAt = %v, want %d", status.StartAt, startAt)
+			}
+		})
+	}
+}
+
+func TestAttackReconcileSchedule(t *testing.T) {
+	timeOf := func(d time.Duration) *metaV1.Time {
+		tm := metaV1.NewTime(time.Now().Add(d))
+		return &tm
+	}
+
+	tests := []struct {
+		name      string
+		startAt   *metaV1.Time
+		deadline  *metaV1.Time
+		wantJob   bool
+		wantPhase vegetaV2.AttackPhase
+		// wantRequeue is the maximum of RequeueAfter, which is zero when not requeued
+		wantRequeue time.Duration
+	}{
+		{
+			name:        "before startAt",
+			startAt:     timeOf(time.Hour),
+			wantPhase:   vegetaV2.AttackScheduled,
+			wantRequeue: time.Hour,
+		},
+		{
+			name:      "after startAt",
+			startAt:   timeOf(-time.Minute),
+			wantJob:   true,
+			wantPhase: vegetaV2.AttackPending,
+		},
+		{
+			name:      "after the deadline",
+			startAt:   timeOf(-time.Hour),
+			deadline:  timeOf(-time.Minute),
+			wantPhase: vegetaV2.AttackFailed,
+		},
+		{
+			name:      "before the deadline",
+			deadline:  timeOf(time.Hour),
+			wantJob:   true,
+			wantPhase: vegetaV2.AttackPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attack := newTestAttack()
+			attack.Spec.StartAt = tt.startAt
+			attack.Spec.Deadline = tt.deadline
+			r := newAttackReconciler(attack)
+
+			result := reconcileAttack(t, r, attack)
+			if exists := getJob(t, r, attack) != nil; exists != tt.wantJob {
+				t.Errorf("Reconcile() created the job = %v, want %v", exists, tt.wantJob)
+			}
+			if phase := getAttack(t, r, attack).Status.Phase; phase != tt.wantPhase {
+				t.Errorf("Reconcile() phase = %s, want %s", phase, tt.wantPhase)
+			}
+			if result.RequeueAfter > tt.wantRequeue || (tt.wantRequeue > 0 && result.RequeueAfter < tt.wantRequeue-time.Minute) {
+				t.Errorf("Reconcile() requeued after %s, want %s", result.RequeueAfter, tt.wantRequeue)
+			}
+		})
+	}
+}
+
+func TestAttackReconcileDeadline(t *testing.T) {
+	// The deadline is a part of the spec hash, so that it is passed by waiting instead of updating the attack.
+	// It is truncated to seconds as well as it is stored
+	deadline := time.Now().Add(2 * time.Second).Truncate(time.Second)
+	attack := newTestAttack()
+	attack.Spec.Deadline = &metaV1.Time{Time: deadline}
+	r := newAttackReconciler(attack)
+	job := startJob(t, r, attack)
+
+	until := time.Until(deadline)
+	if result := reconcileAttack(t, r, attack); result.RequeueAfter <= 0 || result.RequeueAfter > until {
+		t.Errorf("Reconcile() requeued after %s, want until the deadline", result.RequeueAfter)
+	}
+	time.Sleep(time.Until(deadline))
+	reconcileAttack(t, r, attack)
+	stopped := getJob(t, r, attack)
+	if stopped == nil || stopped.UID != job.UID || getStopReason(stopped) != stopReasonDeadlineExceeded {
+		t.Fatalf("Reconcile() job = %+v, want it stopped at the deadline", stopped)
+	}
+
+	finishJob(t, r, stopped)
+	reconcileAttack(t, r, attack)
+	status := getAttack(t, r, attack).Status
+	if status.Phase != vegetaV2.AttackFailed {
+		t.Errorf("Reconcile() phase = %s, want %s", status.Phase, vegetaV2.AttackFailed)
+	}
+	if condition := vegetaV2.FindCondition(status.Conditions, vegetaV2.AttackFailure); condition == nil || condition.Reason != stopReasonDeadlineExceeded {
+		t.Errorf("Reconcile() Failed condition = %+v, want %s", condition, stopReasonDeadlineExceeded)
+	}
+}
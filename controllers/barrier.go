@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	"github.com/go-logr/logr"
+	batchV1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	startBarrierMountPath      = "/var/lib/vegeta-start"
+	defaultStartBarrierTimeout = 5 * time.Minute
+	defaultStartBarrierDelay   = 90 * time.Second
+
+	// Keys of the start config map.
+	// startAt is honored only by the pods of the job, so that startAt of the previous job never releases new pods.
+	startJobKey    = "job"
+	startAtKey     = "startAt"
+	startReasonKey = "reason"
+
+	startReasonTimeout      = "Timeout"
+	startReasonAllPodsReady = "AllPodsReady"
+)
+
+// reconcileStartBarrier writes startAt of the job into the start config map.
+// It is the deadline at first, and is brought forward to the delay after all attack pods are ready.
+func (r *AttackReconciler) reconcileStartBarrier(ctx context.Context, logger logr.Logger, attack *vegetaV2.Attack, job *batchV1.Job) error {
+	barrier := attack.Spec.StartBarrier
+	if barrier == nil || job.UID == "" || isJobFinished(job) || isJobStopped(job) {
+		return nil
+	}
+	timeout := defaultStartBarrierTimeout
+	if barrier.Timeout != nil {
+		timeout = barrier.Timeout.Duration
+	}
+	delay := defaultStartBarrierDelay
+	if barrier.Delay != nil {
+		delay = barrier.Delay.Duration
+	}
+
+	var current v1.ConfigMap
+	if err := r.Get(ctx, client.ObjectKey{Name: attack.Name + "-start", Namespace: attack.Namespace}, &current); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	startAt := job.CreationTimestamp.Add(timeout)
+	reason := startReasonTimeout
+	if current.Data[startJobKey] == string(job.UID) {
+		if current.Data[startReasonKey] == startReasonAllPodsReady {
+			return nil
+		}
+		ready, err := r.countReadyPods(ctx, job)
+		if err != nil {
+			return err
+		}
+		if job.Spec.Parallelism == nil || ready < *job.Spec.Parallelism {
+			return nil
+		}
+		if t := time.Now().Add(delay); t.Before(startAt) {
+			startAt = t
+		}
+		reason = startReasonAllPodsReady
+	}
+
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      attack.Name + "-start",
+			Namespace: attack.Namespace,
+		},
+		Data: map[string]string{
+			startJobKey: string(job.UID),
+			// The pods compare it with `date +%s`, so that it is rounded up to seconds
+			startAtKey:     strconv.FormatInt(startAt.Add(time.Second-1).Unix(), 10),
+			startReasonKey: reason,
+		},
+	}
+	return r.reconcileConfigMap(ctx, logger, attack, configMap, "start config map")
+}
+
+// countReadyPods returns the number of attack pods of the job which are waiting at the start barrier
+func (r *AttackReconciler) countReadyPods(ctx context.Context, job *batchV1.Job) (int32, error) {
+	var pods v1.PodList
+	if err := r.List(
+		ctx,
+		&pods,
+		client.InNamespace(job.Namespace),
+		client.MatchingLabels{"controller-uid": string(job.UID)},
+	); err != nil {
+		return 0, err
+	}
+	var ready int32
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == v1.PodRunning && pod.DeletionTimestamp == nil {
+			ready++
+		}
+	}
+	return ready, nil
+}
+
+// getStartAt returns startAt of the job written in the start config map, or nil when it has not been written yet
+func (r *AttackReconciler) getStartAt(ctx context.Context, attack *vegetaV2.Attack, job *batchV1.Job) (*metaV1.Time, error) {
+	if attack.Spec.StartBarrier == nil || job.UID == "" {
+		return nil, nil
+	}
+	var configMap v1.ConfigMap
+	if err := r.Get(ctx, client.ObjectKey{Name: attack.Name + "-start", Namespace: attack.Namespace}, &configMap); client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+	if configMap.Data[startJobKey] != string(job.UID) {
+		return nil, nil
+	}
+	seconds, err := strconv.ParseInt(configMap.Data[startAtKey], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid startAt %q", configMap.Data[startAtKey])
+	}
+	startAt := metaV1.NewTime(time.Unix(seconds, 0))
+	return &startAt, nil
+}
+
+// buildStartBarrierScript returns the script which waits until startAt of the job of the pod.
+// It polls the mounted config map, since kubelet updates it in place.
+func buildStartBarrierScript(attack *vegetaV2.Attack) []string {
+	if attack.Spec.StartBarrier == nil {
+		return nil
+	}
+	return []string{
+		fmt.Sprintf(`uid=$(sed -n 's/^controller-uid="\(.*\)"$/\1/p' %s/labels)`, podInfoMountPath),
+		fmt.Sprintf(
+			`until [ -n "$stopped" ] || { [ "$(cat %[1]s/%[2]s 2>/dev/null)" = "$uid" ] && [ "$(date +%%s)" -ge "$(cat %[1]s/%[3]s)" ]; }; do sleep 0.1 2>/dev/null || sleep 1; done`,
+			startBarrierMountPath,
+			startJobKey,
+			startAtKey,
+		),
+	}
+}
+
+// buildStartBarrierVolumeSource returns the volume of the start config map, or nil without start barrier.
+// It is optional, since the config map is written after the job is created.
+func buildStartBarrierVolumeSource(attack *vegetaV2.Attack) *v1.VolumeSource {
+	if attack.Spec.StartBarrier == nil {
+		return nil
+	}
+	optional := true
+	return &v1.VolumeSource{
+		ConfigMap: &v1.ConfigMapVolumeSource{
+			LocalObjectReference: v1.LocalObjectReference{
+				Name: attack.Name + "-start",
+			},
+			Optional: &optional,
+		},
+	}
+}
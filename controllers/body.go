@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	bodiesHashAnnotation = "vegeta.kaidotdev.github.io/bodies-hash"
+)
+
+// reconcileBodies makes the bodies available to the attack pods.
+// Inline bodies are put into the bodies config map, and the referenced ones are mounted directly.
+// It returns the hash of all bodies, which changes the pod template when any of them changes.
+func (r *AttackReconciler) reconcileBodies(ctx context.Context, logger logr.Logger, attack *vegetaV2.Attack) (string, vegetaV2.Condition, error) {
+	condition := vegetaV2.Condition{
+		Type:               vegetaV2.AttackBodiesAvailable,
+		Status:             metaV1.ConditionFalse,
+		ObservedGeneration: attack.Generation,
+	}
+
+	if len(attack.Spec.Bodies) == 0 {
+		condition.Status = metaV1.ConditionTrue
+		condition.Reason = "NoBodies"
+		return "", condition, nil
+	}
+
+	// Values are hashed in the order of bodies, so that the hash is stable
+	values := make([]interface{}, 0, len(attack.Spec.Bodies)+1)
+	if configMap := r.buildBodiesConfigMap(attack); configMap != nil {
+		if err := r.reconcileConfigMap(ctx, logger, attack, configMap, "bodies config map"); err != nil {
+			return "", condition, err
+		}
+		values = append(values, configMap.Annotations[specHashAnnotation])
+	}
+
+	for _, body := range attack.Spec.Bodies {
+		source := body.ValueFrom
+		switch {
+		case source == nil:
+		case (source.ConfigMapKeyRef == nil) != (source.SecretKeyRef == nil):
+			value, err := r.resolveKeyRef(ctx, attack, source.ConfigMapKeyRef, source.SecretKeyRef, fmt.Sprintf(" of body %q", body.Name), &condition)
+			if value == nil || err != nil {
+				return "", condition, err
+			}
+			values = append(values, value)
+		default:
+			condition.Reason = "InvalidBodySource"
+			condition.Message = fmt.Sprintf("Exactly one of configMapKeyRef and secretKeyRef must be specified in valueFrom of body %q", body.Name)
+			return "", condition, nil
+		}
+	}
+
+	condition.Status = metaV1.ConditionTrue
+	condition.Reason = "BodiesAvailable"
+	condition.Message = fmt.Sprintf("%d bodies are available", len(attack.Spec.Bodies))
+	hash, err := computeHash(values)
+	return hash, condition, err
+}
+
+// buildBodiesConfigMap returns the config map of inline bodies, or nil when there are none
+func (r *AttackReconciler) buildBodiesConfigMap(attack *vegetaV2.Attack) *v1.ConfigMap {
+	if !hasInlineBodies(attack) {
+		return nil
+	}
+
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      attack.Name + "-bodies",
+			Namespace: attack.Namespace,
+		},
+		Data:       map[string]string{},
+		BinaryData: map[string][]byte{},
+	}
+	for _, body := range attack.Spec.Bodies {
+		switch {
+		case body.ValueFrom != nil:
+		case len(body.BinaryData) > 0:
+			configMap.BinaryData[body.Name] = body.BinaryData
+		default:
+			configMap.Data[body.Name] = body.Data
+		}
+	}
+	return configMap
+}
+
+func hasInlineBodies(attack *vegetaV2.Attack) bool {
+	for _, body := range attack.Spec.Bodies {
+		if body.ValueFrom == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// buildBodiesVolumeSource returns the volume which has each body at its name, or nil when there are no bodies.
+// The bodies may come from different objects, so that they are projected into a single volume.
+func buildBodiesVolumeSource(attack *vegetaV2.Attack) *v1.VolumeSource {
+	if len(attack.Spec.Bodies) == 0 {
+		return nil
+	}
+
+	var sources []v1.VolumeProjection
+	var inlineItems []v1.KeyToPath
+	for _, body := range attack.Spec.Bodies {
+		item := v1.KeyToPath{
+			Key:  body.Name,
+			Path: body.Name,
+		}
+		source := body.ValueFrom
+		switch {
+		case source == nil:
+			inlineItems = append(inlineItems, item)
+		case source.ConfigMapKeyRef != nil:
+			item.Key = source.ConfigMapKeyRef.Key
+			sources = append(sources, v1.VolumeProjection{
+				ConfigMap: &v1.ConfigMapProjection{
+					LocalObjectReference: source.ConfigMapKeyRef.LocalObjectReference,
+					Items:                []v1.KeyToPath{item},
+				},
+			})
+		case source.SecretKeyRef != nil:
+			item.Key = source.SecretKeyRef.Key
+			sources = append(sources, v1.VolumeProjection{
+				Secret: &v1.SecretProjection{
+					LocalObjectReference: source.SecretKeyRef.LocalObjectReference,
+					Items:                []v1.KeyToPath{item},
+				},
+			})
+		}
+	}
+	if len(inlineItems) > 0 {
+		sources = append(sources, v1.VolumeProjection{
+			ConfigMap: &v1.ConfigMapProjection{
+				LocalObjectReference: v1.LocalObjectReference{
+					Name: attack.Name + "-bodies",
+				},
+				Items: inlineItems,
+			},
+		})
+	}
+
+	return &v1.VolumeSource{
+		Projected: &v1.ProjectedVolumeSource{
+			Sources: sources,
+		},
+	}
+}
@@ -0,0 +1,323 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	"github.com/go-logr/logr"
+	"github.com/robfig/cron/v3"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/tools/reference"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	scheduledTimeAnnotation = "vegeta.kaidotdev.github.io/scheduled-at"
+	// maxMissedRuns is the same limit as CronJob, beyond which missed runs are warned and not walked through one by one
+	maxMissedRuns = 100
+	// replaceRecheckInterval is how often the attacks replaced by Replace policy are checked until they are gone,
+	// in addition to their deletion events
+	replaceRecheckInterval = 5 * time.Second
+)
+
+type CronAttackReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+func (r *CronAttackReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	cronAttack := &vegetaV2.CronAttack{}
+	ctx := context.Background()
+	logger := r.Log.WithValues("cronattack", req.NamespacedName)
+	if err := r.Get(ctx, req.NamespacedName, cronAttack); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	var attacks vegetaV2.AttackList
+	if err := r.List(
+		ctx,
+		&attacks,
+		client.InNamespace(req.Namespace),
+		client.MatchingFields{ownerKey: req.Name},
+	); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var activeAttacks, successfulAttacks, failedAttacks []*vegetaV2.Attack
+	var mostRecentTime *time.Time
+	for i := range attacks.Items {
+		attack := &attacks.Items[i]
+		switch {
+		case isAttackFailed(attack):
+			failedAttacks = append(failedAttacks, attack)
+		case isAttackFinished(attack):
+			successfulAttacks = append(successfulAttacks, attack)
+		default:
+			activeAttacks = append(activeAttacks, attack)
+		}
+
+		scheduledTime, err := getScheduledTime(attack)
+		if err != nil {
+			logger.Error(err, "unable to parse schedule time for child attack", "attack", attack.Name)
+			continue
+		}
+		if scheduledTime != nil && (mostRecentTime == nil || mostRecentTime.Before(*scheduledTime)) {
+			mostRecentTime = scheduledTime
+		}
+	}
+
+	status := cronAttack.Status.DeepCopy()
+	// Keep the last schedule time even if the attack has been pruned, not to create missed attacks again
+	if mostRecentTime != nil && (status.LastScheduleTime == nil || status.LastScheduleTime.Time.Before(*mostRecentTime)) {
+		status.LastScheduleTime = &metaV1.Time{Time: *mostRecentTime}
+	}
+	status.Active = nil
+	for _, activeAttack := range activeAttacks {
+		attackRef, err := reference.GetReference(r.Scheme, activeAttack)
+		if err != nil {
+			logger.Error(err, "unable to make reference to active attack", "attack", activeAttack.Name)
+			continue
+		}
+		status.Active = append(status.Active, *attackRef)
+	}
+	if !equality.Semantic.DeepEqual(&cronAttack.Status, status) {
+		cronAttack.Status = *status
+		if err := r.Status().Update(ctx, cronAttack); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.pruneAttacks(ctx, cronAttack, failedAttacks, cronAttack.Spec.FailedAttacksHistoryLimit); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.pruneAttacks(ctx, cronAttack, successfulAttacks, cronAttack.Spec.SuccessfulAttacksHistoryLimit); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if cronAttack.Spec.Suspend != nil && *cronAttack.Spec.Suspend {
+		return ctrl.Result{}, nil
+	}
+
+	now := time.Now()
+	missedRun, tooManyMissed, nextRun, err := getNextSchedule(cronAttack, now)
+	if err != nil {
+		// The schedule will not be fixed until the spec is changed, so we don't need to requeue
+		r.Recorder.Eventf(cronAttack, coreV1.EventTypeWarning, "InvalidSchedule", "Unable to parse schedule %q: %s", cronAttack.Spec.Schedule, err)
+		return ctrl.Result{}, nil
+	}
+
+	result := ctrl.Result{RequeueAfter: nextRun.Sub(now)}
+	if missedRun.IsZero() {
+		return result, nil
+	}
+	if tooManyMissed {
+		// Only the latest run is started anyway, and the earlier ones are skipped as usual
+		logger.Info("too many missed start times, set or decrease startingDeadlineSeconds or check clock skew", "missedRun", missedRun)
+		r.Recorder.Eventf(cronAttack, coreV1.EventTypeWarning, "TooManyMissedTimes", "Missed more than %d start times, set or decrease startingDeadlineSeconds or check clock skew", maxMissedRuns)
+	}
+
+	if cronAttack.Spec.StartingDeadlineSeconds != nil &&
+		missedRun.Add(time.Duration(*cronAttack.Spec.StartingDeadlineSeconds)*time.Second).Before(now) {
+		r.Recorder.Eventf(cronAttack, coreV1.EventTypeWarning, "MissSchedule", "Missed starting window for %s", missedRun.Format(time.RFC3339))
+		return result, nil
+	}
+
+	if cronAttack.Spec.ConcurrencyPolicy == vegetaV2.ForbidConcurrent && len(activeAttacks) > 0 {
+		r.Recorder.Eventf(cronAttack, coreV1.EventTypeNormal, "AttackAlreadyActive", "Not starting attack because prior execution is running and concurrency policy is Forbid")
+		return result, nil
+	}
+
+	if cronAttack.Spec.ConcurrencyPolicy == vegetaV2.ReplaceConcurrent && len(activeAttacks) > 0 {
+		for _, activeAttack := range activeAttacks {
+			if activeAttack.DeletionTimestamp != nil {
+				continue
+			}
+			if err := r.Delete(ctx, activeAttack, client.PropagationPolicy(metaV1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, err
+			}
+			r.Recorder.Eventf(cronAttack, coreV1.EventTypeNormal, "SuccessfulDeleted", "Deleted attack: %q", activeAttack.Name)
+		}
+		// The new attack is created after the old ones are gone, whose pods may keep sending requests until their
+		// results have been collected by the finalizer
+		logger.V(1).Info("waiting for replaced attacks to be deleted", "attacks", len(activeAttacks))
+		return ctrl.Result{RequeueAfter: replaceRecheckInterval}, nil
+	}
+
+	attack, err := r.buildAttack(cronAttack, missedRun)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.Create(ctx, attack); err != nil {
+		if errors.IsAlreadyExists(err) {
+			return result, nil
+		}
+		return ctrl.Result{}, err
+	}
+	r.Recorder.Eventf(cronAttack, coreV1.EventTypeNormal, "SuccessfulCreated", "Created attack: %q", attack.Name)
+	logger.V(1).Info("create", "attack", attack.Name)
+
+	return result, nil
+}
+
+func (r *CronAttackReconciler) pruneAttacks(ctx context.Context, cronAttack *vegetaV2.CronAttack, attacks []*vegetaV2.Attack, limit *int32) error {
+	if limit == nil || int32(len(attacks)) <= *limit {
+		return nil
+	}
+
+	sort.Slice(attacks, func(i, j int) bool {
+		return attacks[i].CreationTimestamp.Before(&attacks[j].CreationTimestamp)
+	})
+	for _, attack := range attacks[:int32(len(attacks))-*limit] {
+		if err := r.Delete(ctx, attack, client.PropagationPolicy(metaV1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		r.Recorder.Eventf(cronAttack, coreV1.EventTypeNormal, "SuccessfulDeleted", "Deleted attack: %q", attack.Name)
+	}
+	return nil
+}
+
+func (r *CronAttackReconciler) buildAttack(cronAttack *vegetaV2.CronAttack, scheduledTime time.Time) (*vegetaV2.Attack, error) {
+	annotations := map[string]string{}
+	for k, v := range cronAttack.Spec.AttackTemplate.Annotations {
+		annotations[k] = v
+	}
+	annotations[scheduledTimeAnnotation] = scheduledTime.Format(time.RFC3339)
+
+	labels := map[string]string{}
+	for k, v := range cronAttack.Spec.AttackTemplate.Labels {
+		labels[k] = v
+	}
+
+	attack := &vegetaV2.Attack{
+		ObjectMeta: metaV1.ObjectMeta{
+			// Same naming as CronJob, which is deterministic to avoid creating the same attack twice
+			Name:        fmt.Sprintf("%s-%d", cronAttack.Name, scheduledTime.Unix()/60),
+			Namespace:   cronAttack.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: *cronAttack.Spec.AttackTemplate.Spec.DeepCopy(),
+	}
+	if err := controllerutil.SetControllerReference(cronAttack, attack, r.Scheme); err != nil {
+		return nil, err
+	}
+	return attack, nil
+}
+
+// getNextSchedule returns the latest missed run, whether more than maxMissedRuns runs were missed, and the next run after now.
+func getNextSchedule(cronAttack *vegetaV2.CronAttack, now time.Time) (time.Time, bool, time.Time, error) {
+	schedule, err := cron.ParseStandard(cronAttack.Spec.Schedule)
+	if err != nil {
+		return time.Time{}, false, time.Time{}, err
+	}
+
+	var earliestTime time.Time
+	if cronAttack.Status.LastScheduleTime != nil {
+		earliestTime = cronAttack.Status.LastScheduleTime.Time
+	} else {
+		earliestTime = cronAttack.CreationTimestamp.Time
+	}
+	if cronAttack.Spec.StartingDeadlineSeconds != nil {
+		schedulingDeadline := now.Add(-time.Second * time.Duration(*cronAttack.Spec.StartingDeadlineSeconds))
+		if schedulingDeadline.After(earliestTime) {
+			earliestTime = schedulingDeadline
+		}
+	}
+	if earliestTime.After(now) {
+		return time.Time{}, false, schedule.Next(now), nil
+	}
+
+	var lastMissed time.Time
+	missed := 0
+	for t := schedule.Next(earliestTime); !t.After(now); t = schedule.Next(t) {
+		lastMissed = t
+		missed++
+		if missed > maxMissedRuns {
+			// Skip to the latest run by looking back from now, not to walk through every run after a long outage
+			for back := time.Minute; ; back *= 2 {
+				from := now.Add(-back)
+				if !from.After(lastMissed) {
+					from = lastMissed
+				}
+				if latest := schedule.Next(from); !latest.After(now) {
+					for ; !latest.After(now); latest = schedule.Next(latest) {
+						lastMissed = latest
+					}
+					break
+				}
+				if from.Equal(lastMissed) {
+					break
+				}
+			}
+			return lastMissed, true, schedule.Next(now), nil
+		}
+	}
+	return lastMissed, false, schedule.Next(now), nil
+}
+
+func getScheduledTime(attack *vegetaV2.Attack) (*time.Time, error) {
+	raw, ok := attack.Annotations[scheduledTimeAnnotation]
+	if !ok || raw == "" {
+		return nil, nil
+	}
+	scheduledTime, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &scheduledTime, nil
+}
+
+func isAttackFinished(attack *vegetaV2.Attack) bool {
+	switch attack.Status.Phase {
+	case vegetaV2.AttackSucceeded, vegetaV2.AttackFailed, vegetaV2.AttackAborted, vegetaV2.AttackCancelled:
+		return true
+	}
+	return false
+}
+
+// isAttackFailed returns true when the attack failed, was aborted or cancelled, or did not pass its thresholds
+func isAttackFailed(attack *vegetaV2.Attack) bool {
+	if attack.Status.Phase == vegetaV2.AttackFailed || attack.Status.Phase == vegetaV2.AttackAborted || attack.Status.Phase == vegetaV2.AttackCancelled {
+		return true
+	}
+	passed := vegetaV2.FindCondition(attack.Status.Conditions, vegetaV2.AttackPassed)
+	return attack.Status.Phase == vegetaV2.AttackSucceeded && passed != nil && passed.Status == metaV1.ConditionFalse
+}
+
+func (r *CronAttackReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(&vegetaV2.Attack{}, ownerKey, func(rawObj runtime.Object) []string {
+		attack := rawObj.(*vegetaV2.Attack)
+		owner := metaV1.GetControllerOf(attack)
+		if owner == nil {
+			return nil
+		}
+		if owner.Kind != "CronAttack" {
+			return nil
+		}
+
+		return []string{owner.Name}
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&vegetaV2.CronAttack{}).
+		Owns(&vegetaV2.Attack{}).
+		Complete(r)
+}
@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func init() {
+	// The fake client decodes objects by the scheme of client-go, whatever scheme it is given
+	if err := vegetaV2.AddToScheme(scheme.Scheme); err != nil {
+		panic(err)
+	}
+}
+
+// newFakeClient returns the fake client of the objects.
+// It ignores client.MatchingFields, so that the objects must not include those of other owners.
+func newFakeClient(objs ...runtime.Object) client.Client {
+	return fake.NewFakeClientWithScheme(scheme.Scheme, objs...)
+}
+
+func TestGetNextSchedule(t *testing.T) {
+	at := func(s string) time.Time {
+		tm, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	seconds := func(n int64) *int64 {
+		return &n
+	}
+
+	tests := []struct {
+		name                    string
+		schedule                string
+		creationTimestamp       string
+		lastScheduleTime        string
+		startingDeadlineSeconds *int64
+		now                     string
+		wantLastMissed          string
+		wantTooManyMissed       bool
+		wantNext                string
+		wantErr                 bool
+	}{
+		{
+			name:              "nothing missed",
+			schedule:          "* * * * *",
+			creationTimestamp: "2020-01-01T10:00:30Z",
+			now:               "2020-01-01T10:00:59Z",
+			wantNext:          "2020-01-01T10:01:00Z",
+		},
+		{
+			name:              "created in the future",
+			schedule:          "* * * * *",
+			creationTimestamp: "2020-01-01T10:05:00Z",
+			now:               "2020-01-01T10:00:30Z",
+			wantNext:          "2020-01-01T10:01:00Z",
+		},
+		{
+			name:              "missed since creation",
+			schedule:          "* * * * *",
+			creationTimestamp: "2020-01-01T10:00:30Z",
+			now:               "2020-01-01T10:02:30Z",
+			wantLastMissed:    "2020-01-01T10:02:00Z",
+			wantNext:          "2020-01-01T10:03:00Z",
+		},
+		{
+			name:              "missed since the last schedule",
+			schedule:          "*/5 * * * *",
+			creationTimestamp: "2020-01-01T00:00:00Z",
+			lastScheduleTime:  "2020-01-01T10:00:00Z",
+			now:               "2020-01-01T10:05:00Z",
+			wantLastMissed:    "2020-01-01T10:05:00Z",
+			wantNext:          "2020-01-01T10:10:00Z",
+		},
+		{
+			name:                    "missed before the starting deadline",
+			schedule:                "*/5 * * * *",
+			creationTimestamp:       "2020-01-01T10:00:30Z",
+			startingDeadlineSeconds: seconds(60),
+			now:                     "2020-01-01T10:06:30Z",
+			wantNext:                "2020-01-01T10:10:00Z",
+		},
+		{
+			name:                    "missed within the starting deadline",
+			schedule:                "*/5 * * * *",
+			creationTimestamp:       "2020-01-01T00:00:00Z",
+			startingDeadlineSeconds: seconds(120),
+			now:                     "2020-01-01T10:06:30Z",
+			wantLastMissed:          "2020-01-01T10:05:00Z",
+			wantNext:                "2020-01-01T10:10:00Z",
+		},
+		{
+			name:              "too many missed",
+			schedule:          "* * * * *",
+			creationTimestamp: "2020-01-01T00:00:30Z",
+			now:               "2020-01-02T10:00:30Z",
+			wantLastMissed:    "2020-01-02T10:00:00Z",
+			wantTooManyMissed: true,
+			wantNext:          "2020-01-02T10:01:00Z",
+		},
+		{
+			name:              "too many missed with a sparse schedule",
+			schedule:          "0 * * * *",
+			creationTimestamp: "2020-01-01T00:30:00Z",
+			now:               "2020-01-10T10:30:00Z",
+			wantLastMissed:    "2020-01-10T10:00:00Z",
+			wantTooManyMissed: true,
+			wantNext:          "2020-01-10T11:00:00Z",
+		},
+		{
+			name:              "invalid schedule",
+			schedule:          "invalid",
+			creationTimestamp: "2020-01-01T00:00:00Z",
+			now:               "2020-01-01T00:00:00Z",
+			wantErr:           true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cronAttack := &vegetaV2.CronAttack{
+				ObjectMeta: metaV1.ObjectMeta{
+					CreationTimestamp: metaV1.NewTime(at(tt.creationTimestamp)),
+				},
+				Spec: vegetaV2.CronAttackSpec{
+					Schedule:                tt.schedule,
+					StartingDeadlineSeconds: tt.startingDeadlineSeconds,
+				},
+			}
+			if tt.lastScheduleTime != "" {
+				lastScheduleTime := metaV1.NewTime(at(tt.lastScheduleTime))
+				cronAttack.Status.LastScheduleTime = &lastScheduleTime
+			}
+
+			lastMissed, tooManyMissed, next, err := getNextSchedule(cronAttack, at(tt.now))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getNextSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var wantLastMissed time.Time
+			if tt.wantLastMissed != "" {
+				wantLastMissed = at(tt.wantLastMissed)
+			}
+			if !lastMissed.Equal(wantLastMissed) {
+				t.Errorf("getNextSchedule() lastMissed = %v, want %v", lastMissed, wantLastMissed)
+			}
+			if tooManyMissed != tt.wantTooManyMissed {
+				t.Errorf("getNextSchedule() tooManyMissed = %v, want %v", tooManyMissed, tt.wantTooManyMissed)
+			}
+			if !next.Equal(at(tt.wantNext)) {
+				t.Errorf("getNextSchedule() next = %v, want %v", next, at(tt.wantNext))
+			}
+		})
+	}
+}
+
+func TestCronAttackReconcileReplace(t *testing.T) {
+	now := time.Now()
+	cronAttack := &vegetaV2.CronAttack{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:              "nightly",
+			Namespace:         "default",
+			UID:               "cron-attack",
+			CreationTimestamp: metaV1.NewTime(now.Add(-time.Hour)),
+		},
+		Spec: vegetaV2.CronAttackSpec{
+			Schedule:          "* * * * *",
+			ConcurrencyPolicy: vegetaV2.ReplaceConcurrent,
+			AttackTemplate: vegetaV2.AttackTemplateSpec{
+				Spec: vegetaV2.AttackSpec{Scenario: "GET http://example.com/"},
+			},
+		},
+	}
+	previous := &vegetaV2.Attack{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:        "nightly-previous",
+			Namespace:   "default",
+			Annotations: map[string]string{scheduledTimeAnnotation: now.Add(-10 * time.Minute).Format(time.RFC3339)},
+		},
+	}
+	if err := controllerutil.SetControllerReference(cronAttack, previous, scheme.Scheme); err != nil {
+		t.Fatal(err)
+	}
+	r := &CronAttackReconciler{
+		Client:   newFakeClient(cronAttack, previous),
+		Log:      log.NullLogger{},
+		Scheme:   scheme.Scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKey{Name: cronAttack.Name, Namespace: cronAttack.Namespace}}
+	listAttacks := func() []string {
+		var attacks vegetaV2.AttackList
+		if err := r.List(context.Background(), &attacks); err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, attack := range attacks.Items {
+			names = append(names, attack.Name)
+		}
+		return names
+	}
+
+	result, err := r.Reconcile(req)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if result.RequeueAfter != replaceRecheckInterval {
+		t.Errorf("Reconcile() requeued after %s while the replaced attack is deleted, want %s", result.RequeueAfter, replaceRecheckInterval)
+	}
+	if names := listAttacks(); len(names) != 0 {
+		t.Fatalf("Reconcile() left attacks %q, want the previous one deleted and no new one created yet", names)
+	}
+
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if names := listAttacks(); len(names) != 1 || names[0] == previous.Name {
+		t.Errorf("Reconcile() created attacks %q, want the new one after the previous one has gone", names)
+	}
+}
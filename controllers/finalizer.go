@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	"github.com/go-logr/logr"
+	batchV1 "k8s.io/api/batch/v1"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// resultsFinalizer keeps the deleted Attack until the results of its pods are exported
+	resultsFinalizer = "vegeta.kaidotdev.github.io/results"
+	// finalizeInterval is how often the deleted Attack is checked while its pods are stopping
+	finalizeInterval       = 10 * time.Second
+	defaultFinalizeTimeout = 5 * time.Minute
+)
+
+func hasFinalizer(attack *vegetaV2.Attack, finalizer string) bool {
+	for _, f := range attack.Finalizers {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// isFinalizeExpired returns true when the deleted Attack has waited for its pods to stop longer than FinalizeTimeout
+func (r *AttackReconciler) isFinalizeExpired(attack *vegetaV2.Attack) bool {
+	return attack.DeletionTimestamp != nil && time.Since(attack.DeletionTimestamp.Time) >= r.FinalizeTimeout
+}
+
+// finalize stops the attack pods of the deleted Attack and exports their results, and then releases the finalizer.
+// The finalizer is released with the results collected until then after FinalizeTimeout,
+// so that a stuck pod never blocks the deletion.
+func (r *AttackReconciler) finalize(ctx context.Context, logger logr.Logger, attack *vegetaV2.Attack) (ctrl.Result, error) {
+	if !hasFinalizer(attack, resultsFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	var job batchV1.Job
+	if err := r.Get(ctx, client.ObjectKey{Name: attack.Name + "-attack", Namespace: attack.Namespace}, &job); client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	}
+	expired := r.isFinalizeExpired(attack)
+	if job.UID != "" {
+		if !isJobFinished(&job) && !isJobStopped(&job) {
+			if err := r.stopJob(ctx, logger, attack, &job, stopReasonDeleted); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		if isJobStopped(&job) && job.Status.Active > 0 {
+			if err := r.stopPods(ctx, logger, &job); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		// The status is deleted along with Attack, so that the results of the attack stopped by the deletion are
+		// exported into the report config map even without reports
+		reports := attack.Spec.Reports
+		if len(reports) == 0 && getStopReason(&job) == stopReasonDeleted {
+			reports = []vegetaV2.ReportOutput{{Type: vegetaV2.JSONReportType}}
+		}
+		if err := r.updateStatus(ctx, logger, attack, &job, reports); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if !expired && (job.Status.Active > 0 || r.live.following(client.ObjectKey{Name: attack.Name, Namespace: attack.Namespace})) {
+			requeueAfter := finalizeInterval
+			if remaining := time.Until(attack.DeletionTimestamp.Add(r.FinalizeTimeout)); remaining < requeueAfter {
+				requeueAfter = remaining
+			}
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+		if expired && job.Status.Active > 0 {
+			r.Recorder.Eventf(attack, coreV1.EventTypeWarning, "FinalizeTimeout", "Released attack without waiting for %d attack pods to stop after %s", job.Status.Active, r.FinalizeTimeout)
+		}
+	}
+
+	if err := r.releaseReport(ctx, logger, attack); err != nil {
+		return ctrl.Result{}, err
+	}
+	controllerutil.RemoveFinalizer(attack, resultsFinalizer)
+	if err := r.Update(ctx, attack); err != nil {
+		return ctrl.Result{}, err
+	}
+	logger.V(1).Info("finalize", "attack", attack.Name)
+	return ctrl.Result{}, nil
+}
+
+// releaseReport removes the owner reference to Attack from the report config maps, so that they outlive Attack
+func (r *AttackReconciler) releaseReport(ctx context.Context, logger logr.Logger, attack *vegetaV2.Attack) error {
+	// The report config maps are numbered without gaps, since the stale ones are deleted
+	for i := 0; ; i++ {
+		var configMap coreV1.ConfigMap
+		if err := r.Get(ctx, client.ObjectKey{Name: reportConfigMapName(attack, i), Namespace: attack.Namespace}, &configMap); errors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		patch := client.MergeFrom(configMap.DeepCopy())
+		var ownerReferences []metaV1.OwnerReference
+		for _, ownerReference := range configMap.OwnerReferences {
+			if ownerReference.UID != attack.UID {
+				ownerReferences = append(ownerReferences, ownerReference)
+			}
+		}
+		if len(ownerReferences) == len(configMap.OwnerReferences) {
+			continue
+		}
+		configMap.OwnerReferences = ownerReferences
+		if err := r.Patch(ctx, &configMap, patch); err != nil {
+			return err
+		}
+		logger.V(1).Info("release", "configMap", configMap.Name)
+	}
+}
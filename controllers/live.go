@@ -0,0 +1,451 @@
+package controllers
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// liveWindow is the period which the achieved rate and in-flight requests are averaged over
+	liveWindow = 10 * time.Second
+	// defaultVegetaRate is the rate of vegeta when -rate is not specified
+	defaultVegetaRate = 50
+	// maxLiveRetries is how many times the stream of a pod is opened again after errors.
+	// The results of the pod are read from its log after it has finished anyway.
+	maxLiveRetries = 5
+)
+
+var (
+	liveLabels = []string{"namespace", "attack", "pod"}
+
+	requestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "vegeta_attack_requests_total",
+			Help: "Number of requests sent by the attack pod, partitioned by status code",
+		},
+		append(liveLabels, "code"),
+	)
+	requestLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "vegeta_attack_request_duration_seconds",
+			Help:    "Latency of requests sent by the attack pod",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 16),
+		},
+		liveLabels,
+	)
+	requestedRate = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "vegeta_attack_requested_rate",
+			Help: "Requests per second which the attack pod is requested to send at the moment",
+		},
+		liveLabels,
+	)
+	achievedRate = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "vegeta_attack_achieved_rate",
+			Help: "Requests per second which the attack pod has sent over the last 10 seconds",
+		},
+		liveLabels,
+	)
+	inFlightRequests = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "vegeta_attack_in_flight_requests",
+			Help: "Requests in flight of the attack pod, estimated from the rate and latency over the last 10 seconds",
+		},
+		liveLabels,
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(requestsTotal, requestLatency, requestedRate, achievedRate, inFlightRequests)
+}
+
+// liveStreamer follows the results of running attack pods, which are streamed to the log of results container,
+// and exports them as Prometheus metrics while the attack is running
+type liveStreamer struct {
+	mu      sync.Mutex
+	log     logr.Logger
+	attacks map[types.NamespacedName]map[types.UID]*podStream
+}
+
+func newLiveStreamer(log logr.Logger) *liveStreamer {
+	return &liveStreamer{
+		log:     log,
+		attacks: map[types.NamespacedName]map[types.UID]*podStream{},
+	}
+}
+
+// podStream is the state of the metrics of a pod
+type podStream struct {
+	labels prometheus.Labels
+	codes  map[string]struct{}
+	stream io.Closer
+	// deleted is set when the metrics are deleted, not to export them again by the stream being closed
+	deleted bool
+
+	steps []attackStep
+	rates []float64
+	start time.Time
+	// results are used instead of reading the log again once they have been followed to the end
+	results *podResults
+	// closed is set when the stream has ended, and finished is set only when the results have been followed to the end
+	closed   bool
+	finished bool
+	// followed is the number of results followed, which are skipped when the stream is opened again after an error
+	followed int
+	retries  int
+	// Results are bucketed by the second of their timestamps to compute the metrics over liveWindow
+	counts    map[int64]int
+	latencies map[int64]time.Duration
+	// Results are also bucketed by the second of their completion over abortWindow to evaluate abort conditions,
+	// only while they are specified
+	abortWindow time.Duration
+	completions map[int64]*resultMetrics
+}
+
+// watch starts to follow the results of the pod unless it is already followed.
+// The stream which ended with an error is opened again up to maxLiveRetries times.
+func (s *liveStreamer) watch(clientset kubernetes.Interface, attack *vegetaV2.Attack, pod *coreV1.Pod) {
+	attackName := types.NamespacedName{Name: attack.Name, Namespace: attack.Namespace}
+
+	s.mu.Lock()
+	pods, ok := s.attacks[attackName]
+	if !ok {
+		pods = map[types.UID]*podStream{}
+		s.attacks[attackName] = pods
+	}
+	p, ok := pods[pod.UID]
+	if ok && (!p.closed || p.finished || p.retries >= maxLiveRetries) {
+		s.mu.Unlock()
+		return
+	}
+	if ok {
+		p.retries++
+	} else {
+		p = &podStream{
+			labels: prometheus.Labels{
+				"namespace": attack.Namespace,
+				"attack":    attack.Name,
+				"pod":       pod.Name,
+			},
+			codes:     map[string]struct{}{},
+			steps:     buildAttackSteps(attack),
+			counts:    map[int64]int{},
+			latencies: map[int64]time.Duration{},
+			results:   newPodResults(),
+			// The bucket of the oldest second is partially in the window
+			abortWindow: maxAbortWindow(attack) + time.Second,
+			completions: map[int64]*resultMetrics{},
+		}
+		for _, step := range p.steps {
+			r := podRate(attack, step.rate)
+			perSecond := r.perSecond()
+			switch {
+			case r.unlimited():
+				// Requests are sent as fast as -max-workers allow
+				perSecond = math.Inf(1)
+			case perSecond == 0:
+				perSecond = defaultVegetaRate
+			}
+			p.rates = append(p.rates, perSecond)
+		}
+		pods[pod.UID] = p
+		requestedRate.With(p.labels).Set(p.rates[0])
+	}
+	// The stream is being opened, so that it is not opened twice
+	p.closed = false
+	skip := p.followed
+	s.mu.Unlock()
+
+	// Opening the stream waits for the API server, which must not block the other streams
+	stream, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &coreV1.PodLogOptions{
+		Container: resultsContainerName,
+		Follow:    true,
+	}).Stream()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		p.closed = true
+		s.log.Error(err, "unable to follow results", "attack", attackName, "pod", pod.Name)
+		return
+	}
+	if p.deleted {
+		_ = stream.Close()
+		return
+	}
+	p.stream = stream
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer stream.Close()
+		// The log is followed from the beginning, so that the results followed before the error are skipped
+		artifacts, err := decodeResultsLog(stream, func(r *result) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if skip > 0 {
+				skip--
+				return
+			}
+			if !p.deleted {
+				p.add(r)
+			}
+		})
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		p.closed = true
+		if p.deleted {
+			return
+		}
+		if err != nil {
+			s.log.Error(err, "unable to follow results", "attack", attackName, "pod", pod.Name)
+		} else {
+			p.results.artifacts = artifacts
+			p.finished = true
+		}
+		// The rates are meaningless after the stream has ended, while the counters are kept until the pod is gone
+		requestedRate.With(p.labels).Set(0)
+		achievedRate.With(p.labels).Set(0)
+		inFlightRequests.With(p.labels).Set(0)
+	}()
+	go func() {
+		// The rates are updated even without results, so that they decay while the target stalls
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				s.mu.Lock()
+				if !p.deleted && !p.closed {
+					p.updateRates(now)
+				}
+				s.mu.Unlock()
+			}
+		}
+	}()
+}
+
+func (p *podStream) add(r *result) {
+	p.followed++
+	code := strconv.Itoa(int(r.Code))
+	p.codes[code] = struct{}{}
+	labels := prometheus.Labels{"code": code}
+	for k, v := range p.labels {
+		labels[k] = v
+	}
+	requestsTotal.With(labels).Inc()
+	requestLatency.With(p.labels).Observe(r.Latency.Seconds())
+	p.results.add(r)
+	if p.abortWindow > time.Second {
+		end := r.End().Unix()
+		metrics, ok := p.completions[end]
+		if !ok {
+			metrics = newResultMetrics()
+			p.completions[end] = metrics
+		}
+		metrics.add(r)
+		for t := range p.completions {
+			if t <= end-int64(p.abortWindow/time.Second) {
+				delete(p.completions, t)
+			}
+		}
+	}
+
+	if p.start.IsZero() || r.Timestamp.Before(p.start) {
+		p.start = r.Timestamp
+	}
+	second := r.Timestamp.Unix()
+	p.counts[second]++
+	p.latencies[second] += r.Latency
+}
+
+// updateRates exports the rates over liveWindow until the second of now
+func (p *podStream) updateRates(now time.Time) {
+	// Results are written when the responses are received, so that the current second is not complete yet
+	second := now.Unix()
+	var count int
+	var latency time.Duration
+	for t := second - int64(liveWindow/time.Second); t < second; t++ {
+		count += p.counts[t]
+		latency += p.latencies[t]
+	}
+	for t := range p.counts {
+		if t < second-int64(liveWindow/time.Second) {
+			delete(p.counts, t)
+			delete(p.latencies, t)
+		}
+	}
+	rate := float64(count) / liveWindow.Seconds()
+	achievedRate.With(p.labels).Set(rate)
+	if count > 0 {
+		// Little's law: requests in flight are the arrival rate multiplied by the mean latency
+		inFlightRequests.With(p.labels).Set(rate * (latency / time.Duration(count)).Seconds())
+	} else {
+		inFlightRequests.With(p.labels).Set(0)
+	}
+	if !p.start.IsZero() {
+		requestedRate.With(p.labels).Set(p.requestedRateAt(now.Sub(p.start)))
+	}
+}
+
+// requestedRateAt returns the rate of the step which runs at the elapsed time since the first request
+func (p *podStream) requestedRateAt(elapsed time.Duration) float64 {
+	var end time.Duration
+	for i, step := range p.steps {
+		duration, err := time.ParseDuration(step.duration)
+		if err != nil {
+			// The step without duration runs forever
+			return p.rates[i]
+		}
+		end += duration
+		if elapsed < end {
+			return p.rates[i]
+		}
+	}
+	return p.rates[len(p.rates)-1]
+}
+
+// delete stops following the pod and deletes its metrics
+func (p *podStream) delete() {
+	p.deleted = true
+	if p.stream != nil {
+		_ = p.stream.Close()
+	}
+	for code := range p.codes {
+		labels := prometheus.Labels{"code": code}
+		for k, v := range p.labels {
+			labels[k] = v
+		}
+		requestsTotal.Delete(labels)
+	}
+	requestLatency.Delete(p.labels)
+	requestedRate.Delete(p.labels)
+	achievedRate.Delete(p.labels)
+	inFlightRequests.Delete(p.labels)
+}
+
+// window returns the metrics of the results of all pods which completed within the window until the latest completion.
+// The window is rounded to seconds.
+func (s *liveStreamer) window(attack types.NamespacedName, window time.Duration) *resultMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var latest int64
+	for _, p := range s.attacks[attack] {
+		for t := range p.completions {
+			if t > latest {
+				latest = t
+			}
+		}
+	}
+	cutoff := latest - int64((window+time.Second-1)/time.Second)
+
+	metrics := newResultMetrics()
+	for _, p := range s.attacks[attack] {
+		for t, m := range p.completions {
+			if t > cutoff {
+				metrics.merge(m)
+			}
+		}
+	}
+	return metrics
+}
+
+// following returns true while any stream of the attack has not ended
+func (s *liveStreamer) following(attack types.NamespacedName) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.attacks[attack] {
+		if !p.closed {
+			return true
+		}
+	}
+	return false
+}
+
+// exhausted returns the UIDs of the pods whose streams ended with errors more than maxLiveRetries times,
+// which are no longer followed until their results are read from the logs after they have finished
+func (s *liveStreamer) exhausted(attack types.NamespacedName) map[types.UID]struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exhausted := map[types.UID]struct{}{}
+	for uid, p := range s.attacks[attack] {
+		if p.closed && !p.finished && p.retries >= maxLiveRetries {
+			exhausted[uid] = struct{}{}
+		}
+	}
+	return exhausted
+}
+
+// starts returns the timestamps of the first results of the pods, keyed by the UIDs of the pods
+func (s *liveStreamer) starts(attack types.NamespacedName) map[types.UID]time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	starts := map[types.UID]time.Time{}
+	for uid, p := range s.attacks[attack] {
+		if !p.start.IsZero() {
+			starts[uid] = p.start
+		}
+	}
+	return starts
+}
+
+// collect returns the results of the pods which have been followed to the end, keyed by the UIDs of the pods
+func (s *liveStreamer) collect(attack types.NamespacedName) map[types.UID]*podResults {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := map[types.UID]*podResults{}
+	for uid, p := range s.attacks[attack] {
+		if p.finished {
+			results[uid] = p.results
+		}
+	}
+	return results
+}
+
+// retain deletes the metrics of pods that no longer exist
+func (s *liveStreamer) retain(attack types.NamespacedName, pods []coreV1.Pod) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := make(map[types.UID]struct{}, len(pods))
+	for _, pod := range pods {
+		existing[pod.UID] = struct{}{}
+	}
+	for uid, p := range s.attacks[attack] {
+		if _, ok := existing[uid]; !ok {
+			p.delete()
+			delete(s.attacks[attack], uid)
+		}
+	}
+}
+
+func (s *liveStreamer) forget(attack types.NamespacedName) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.attacks[attack] {
+		p.delete()
+	}
+	delete(s.attacks, attack)
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestLiveStreamerExhausted(t *testing.T) {
+	// The fake clientset of this version of client-go can not stream logs, so that the API server is faked by a server
+	// which returns the log of any pod which can not be decoded as results
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = io.WriteString(w, "fake logs\n")
+	}))
+	defer server.Close()
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	attack := &vegetaV2.Attack{ObjectMeta: metaV1.ObjectMeta{Name: "sample", Namespace: "default"}}
+	pod := &v1.Pod{ObjectMeta: metaV1.ObjectMeta{Name: "sample-attack-abcde", Namespace: "default", UID: "pod"}}
+	attackName := types.NamespacedName{Name: attack.Name, Namespace: attack.Namespace}
+
+	s := newLiveStreamer(log.NullLogger{})
+	defer s.forget(attackName)
+	for i := 0; i <= maxLiveRetries; i++ {
+		if _, ok := s.exhausted(attackName)[pod.UID]; ok {
+			t.Fatalf("exhausted() contains the pod after %d attempts, want %d", i, maxLiveRetries+1)
+		}
+		s.watch(clientset, attack, pod)
+		deadline := time.Now().Add(5 * time.Second)
+		for s.following(attackName) {
+			if time.Now().After(deadline) {
+				t.Fatalf("the stream of attempt %d did not end", i)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if _, ok := s.exhausted(attackName)[pod.UID]; !ok {
+		t.Errorf("exhausted() does not contain the pod after %d attempts", maxLiveRetries+1)
+	}
+	if _, ok := s.collect(attackName)[pod.UID]; ok {
+		t.Errorf("collect() contains the pod whose results were not followed to the end")
+	}
+}
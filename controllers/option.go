@@ -0,0 +1,347 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	batchV1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	podOptionAnnotation = "vegeta.kaidotdev.github.io/pod-option"
+	// unsupportedOptionsMessage prefixes the termination message of the vegeta container failed by the flag probe
+	unsupportedOptionsMessage = "unsupported options:"
+)
+
+// buildPodOption returns the rate, workers and connections applied to each attack pod.
+// Job pods can not be configured individually, so the rate is divided as a fraction to keep the remainder,
+// e.g. 10 requests across 4 pods is "10/4s" that is 2.5 requests per second for each pod.
+func buildPodOption(attack *vegetaV2.Attack) vegetaV2.PodOption {
+	option := attack.Spec.Option
+	podOption := vegetaV2.PodOption{
+		Workers:     int32Value(option.Workers),
+		Connections: int32Value(option.Connections),
+	}
+	optionRate := parseRate(option.Rate)
+	if len(attack.Spec.Stages) > 0 {
+		// The rate changes by stage, so that it is applied to each step instead
+		optionRate = rate{}
+	}
+	if option.RateMode != vegetaV2.TotalRateMode {
+		podOption.Rate = optionRate.String()
+		return podOption
+	}
+
+	parallelism := getParallelism(attack)
+	podOption.Rate = optionRate.divide(parallelism).String()
+	// Workers and connections are only upper bounds of each pod, so they are rounded up not to starve the rate
+	podOption.Workers = divideCeil(podOption.Workers, parallelism)
+	podOption.Connections = divideCeil(podOption.Connections, parallelism)
+	return podOption
+}
+
+// attackFlag is a flag of `vegeta attack` except for those which vary by step
+type attackFlag struct {
+	name  string
+	value string
+	// Boolean flags need "=", otherwise "false" is regarded as an argument
+	boolean bool
+	// The value is already quoted for sh, e.g. to expand environment variables at run time
+	quoted bool
+}
+
+func (f attackFlag) String() string {
+	switch {
+	case f.boolean && f.value == "true":
+		return "-" + f.name
+	case f.boolean:
+		return fmt.Sprintf("-%s=%s", f.name, f.value)
+	case f.quoted:
+		return fmt.Sprintf("-%s %s", f.name, f.value)
+	default:
+		return fmt.Sprintf("-%s %s", f.name, shellQuote(f.value))
+	}
+}
+
+// buildAttackFlags returns the flags shared by all steps, omitting the options left to the default of vegeta
+func buildAttackFlags(attack *vegetaV2.Attack, podOption vegetaV2.PodOption) []attackFlag {
+	option := attack.Spec.Option
+	var flags []attackFlag
+	if podOption.Connections != 0 {
+		flags = append(flags, attackFlag{name: "connections", value: strconv.Itoa(int(podOption.Connections))})
+	}
+	if option.Timeout != nil {
+		flags = append(flags, attackFlag{name: "timeout", value: formatDuration(option.Timeout.Duration)})
+	}
+	if podOption.Workers != 0 {
+		flags = append(flags, attackFlag{name: "workers", value: strconv.Itoa(int(podOption.Workers))})
+	}
+	if option.MaxWorkers != nil {
+		flags = append(flags, attackFlag{name: "max-workers", value: strconv.FormatInt(*option.MaxWorkers, 10)})
+	}
+	if option.Format != "" {
+		flags = append(flags, attackFlag{name: "format", value: option.Format})
+	}
+	if option.Keepalive != nil && !*option.Keepalive {
+		flags = append(flags, attackFlag{name: "keepalive", value: "false", boolean: true})
+	}
+	for _, header := range option.Headers {
+		flags = append(flags, attackFlag{name: "header", value: fmt.Sprintf("%s: %s", header.Name, header.Value)})
+	}
+	for i, header := range attack.Spec.Headers {
+		if header.ValueFrom == nil {
+			flags = append(flags, attackFlag{name: "header", value: fmt.Sprintf("%s: %s", header.Name, header.Value)})
+			continue
+		}
+		// The value is expanded by sh in the pod, so that it never appears in the job
+		flags = append(flags, attackFlag{
+			name:   "header",
+			value:  shellQuote(header.Name+": ") + fmt.Sprintf(`"$%s"`, headerEnvName(i)),
+			quoted: true,
+		})
+	}
+	if (option.Insecure != nil && *option.Insecure) || (attack.Spec.TLS != nil && attack.Spec.TLS.InsecureSkipVerify) {
+		flags = append(flags, attackFlag{name: "insecure", value: "true", boolean: true})
+	}
+	if option.HTTP2 != nil && !*option.HTTP2 {
+		flags = append(flags, attackFlag{name: "http2", value: "false", boolean: true})
+	}
+	if option.H2C != nil && *option.H2C {
+		flags = append(flags, attackFlag{name: "h2c", value: "true", boolean: true})
+	}
+	if option.Redirects != nil {
+		flags = append(flags, attackFlag{name: "redirects", value: strconv.Itoa(int(*option.Redirects))})
+	}
+	if option.MaxBody != nil {
+		flags = append(flags, attackFlag{name: "max-body", value: strconv.FormatInt(*option.MaxBody, 10)})
+	}
+	if option.Lazy != nil && *option.Lazy {
+		flags = append(flags, attackFlag{name: "lazy", value: "true", boolean: true})
+	}
+	if option.Chunked != nil && *option.Chunked {
+		flags = append(flags, attackFlag{name: "chunked", value: "true", boolean: true})
+	}
+	if option.Name != "" {
+		flags = append(flags, attackFlag{name: "name", value: option.Name})
+	}
+	if option.LocalAddress != "" {
+		flags = append(flags, attackFlag{name: "laddr", value: option.LocalAddress})
+	}
+	if len(option.Resolvers) > 0 {
+		flags = append(flags, attackFlag{name: "resolvers", value: strings.Join(option.Resolvers, ",")})
+	}
+	return append(flags, buildTLSFlags(attack)...)
+}
+
+// headerEnvName returns the environment variable which has the value of the header taken from a Secret
+func headerEnvName(index int) string {
+	return fmt.Sprintf("VEGETA_HEADER_%d", index)
+}
+
+// buildHeaderEnv returns the environment variables referencing the Secrets of the header values
+func buildHeaderEnv(attack *vegetaV2.Attack) []v1.EnvVar {
+	var env []v1.EnvVar
+	for i, header := range attack.Spec.Headers {
+		if header.ValueFrom == nil {
+			continue
+		}
+		env = append(env, v1.EnvVar{
+			Name: headerEnvName(i),
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: header.ValueFrom.SecretKeyRef,
+			},
+		})
+	}
+	return env
+}
+
+// buildFlagProbe returns the script to fail before the attack when the vegeta image does not know some of the flags.
+// The flags are looked up in the usage, since they vary by the version of vegeta and the image is configurable.
+func buildFlagProbe(flags []attackFlag) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, flag := range flags {
+		if !seen[flag.name] {
+			seen[flag.name] = true
+			names = append(names, flag.name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return []string{
+		"unsupported=''",
+		fmt.Sprintf("for flag in %s; do", strings.Join(names, " ")),
+		`  vegeta attack -h 2>&1 | grep -qE "^ +-$flag([[:space:]]|\$)" || unsupported="$unsupported -$flag"`,
+		"done",
+		`if [ -n "$unsupported" ]; then`,
+		fmt.Sprintf(`  echo "%s$unsupported" | tee /dev/termination-log >&2`, unsupportedOptionsMessage),
+		"  exit 1",
+		"fi",
+	}
+}
+
+var shellSafePattern = regexp.MustCompile(`^[A-Za-z0-9_./:,=@%+-]+$`)
+
+// shellQuote quotes the value for sh only when it is needed, to keep the command readable
+func shellQuote(value string) string {
+	if shellSafePattern.MatchString(value) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// formatPodRate returns the value of -rate for each pod to send the given rate
+func formatPodRate(attack *vegetaV2.Attack, r rate) string {
+	return podRate(attack, r).String()
+}
+
+// podRate returns the rate of each pod to send the given rate
+func podRate(attack *vegetaV2.Attack, r rate) rate {
+	if attack.Spec.Option.RateMode != vegetaV2.TotalRateMode {
+		return r
+	}
+	return r.divide(getParallelism(attack))
+}
+
+func getParallelism(attack *vegetaV2.Attack) int32 {
+	if attack.Spec.Parallelism < 1 {
+		return 1
+	}
+	return attack.Spec.Parallelism
+}
+
+// divideCeil divides n by d rounding up with minimum 1, while 0 keeps meaning the default of vegeta
+func divideCeil(n int32, d int32) int32 {
+	if n == 0 {
+		return 0
+	}
+	return (n + d - 1) / d
+}
+
+func int32Value(n *int32) int32 {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
+
+// rate is the number of requests per the time unit, which is the same as -rate of vegeta
+type rate struct {
+	freq int64
+	per  time.Duration
+}
+
+// parseRate returns zero rate, which means the default of vegeta, when the rate is not specified.
+// Invalid rates are rejected by the webhook, so they are regarded as not specified here.
+// Zero rate specified explicitly, which is unlimited with maxWorkers, keeps the time unit to be distinguished.
+func parseRate(value *intstr.IntOrString) rate {
+	if value == nil {
+		return rate{}
+	}
+	freq, per, err := vegetaV2.ParseRate(*value)
+	if err != nil {
+		return rate{}
+	}
+	return rate{freq: freq, per: per}
+}
+
+// ratePerMinute returns the rate closest to the requests per second with the precision of requests per minute
+func ratePerMinute(perSecond float64) rate {
+	perMinute := int64(math.Round(perSecond * 60))
+	if perMinute < 1 {
+		perMinute = 1
+	}
+	if perMinute%60 == 0 {
+		return rate{freq: perMinute / 60, per: time.Second}
+	}
+	return rate{freq: perMinute, per: time.Minute}
+}
+
+// String returns the value of -rate, where empty means the default of vegeta
+func (r rate) String() string {
+	switch {
+	case r.per == 0:
+		return ""
+	case r.freq == 0:
+		return "0"
+	case r.per == time.Second:
+		return strconv.FormatInt(r.freq, 10)
+	default:
+		return fmt.Sprintf("%d/%s", r.freq, formatDuration(r.per))
+	}
+}
+
+// divide divides the rate by n as a fraction to keep the remainder
+func (r rate) divide(n int32) rate {
+	if r.freq%int64(n) == 0 {
+		return rate{freq: r.freq / int64(n), per: r.per}
+	}
+	return rate{freq: r.freq, per: r.per * time.Duration(n)}
+}
+
+// unlimited returns whether the rate is specified as zero, which sends requests as fast as -max-workers allow
+func (r rate) unlimited() bool {
+	return r.freq == 0 && r.per != 0
+}
+
+func (r rate) perSecond() float64 {
+	if r.freq == 0 {
+		return 0
+	}
+	return float64(r.freq) / r.per.Seconds()
+}
+
+// formatDuration formats the duration as short as possible, e.g. "1m" instead of "1m0s"
+func formatDuration(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "0s"
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%ds", d/time.Second)
+	default:
+		return d.String()
+	}
+}
+
+// getPodOption returns the pod option which the pods of the job were created with
+func getPodOption(attack *vegetaV2.Attack, job *batchV1.Job) (*vegetaV2.PodOption, error) {
+	if job.UID == "" {
+		return nil, nil
+	}
+
+	raw, ok := job.Spec.Template.Annotations[podOptionAnnotation]
+	if !ok {
+		// Options are passed to the pods verbatim unless they are divided
+		podOption := buildPodOption(&vegetaV2.Attack{
+			Spec: vegetaV2.AttackSpec{
+				Option: vegetaV2.VegetaOption{
+					Rate:        attack.Spec.Option.Rate,
+					Workers:     attack.Spec.Option.Workers,
+					Connections: attack.Spec.Option.Connections,
+				},
+				Stages: attack.Spec.Stages,
+			},
+		})
+		return &podOption, nil
+	}
+
+	var podOption vegetaV2.PodOption
+	if err := json.Unmarshal([]byte(raw), &podOption); err != nil {
+		return nil, err
+	}
+	return &podOption, nil
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"testing"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestBuildPodOption(t *testing.T) {
+	int32Ptr := func(n int32) *int32 {
+		return &n
+	}
+	int64Ptr := func(n int64) *int64 {
+		return &n
+	}
+	rateOf := func(value intstr.IntOrString) *intstr.IntOrString {
+		return &value
+	}
+
+	tests := []struct {
+		name        string
+		option      vegetaV2.VegetaOption
+		parallelism int32
+		stages      []vegetaV2.Stage
+		want        vegetaV2.PodOption
+	}{
+		{
+			name: "default",
+			want: vegetaV2.PodOption{},
+		},
+		{
+			name: "per pod",
+			option: vegetaV2.VegetaOption{
+				Rate:        rateOf(intstr.FromInt(10)),
+				Workers:     int32Ptr(5),
+				Connections: int32Ptr(100),
+			},
+			parallelism: 4,
+			want:        vegetaV2.PodOption{Rate: "10", Workers: 5, Connections: 100},
+		},
+		{
+			name: "total divisible",
+			option: vegetaV2.VegetaOption{
+				Rate:     rateOf(intstr.FromString("100/1m")),
+				RateMode: vegetaV2.TotalRateMode,
+			},
+			parallelism: 4,
+			want:        vegetaV2.PodOption{Rate: "25/1m"},
+		},
+		{
+			name: "total with remainder",
+			option: vegetaV2.VegetaOption{
+				Rate:        rateOf(intstr.FromInt(10)),
+				RateMode:    vegetaV2.TotalRateMode,
+				Workers:     int32Ptr(5),
+				Connections: int32Ptr(100),
+			},
+			parallelism: 4,
+			want:        vegetaV2.PodOption{Rate: "10/4s", Workers: 2, Connections: 25},
+		},
+		{
+			name: "total without parallelism",
+			option: vegetaV2.VegetaOption{
+				Rate:     rateOf(intstr.FromInt(10)),
+				RateMode: vegetaV2.TotalRateMode,
+			},
+			want: vegetaV2.PodOption{Rate: "10"},
+		},
+		{
+			name: "total unlimited",
+			option: vegetaV2.VegetaOption{
+				Rate:       rateOf(intstr.FromInt(0)),
+				RateMode:   vegetaV2.TotalRateMode,
+				MaxWorkers: int64Ptr(8),
+			},
+			parallelism: 4,
+			want:        vegetaV2.PodOption{Rate: "0"},
+		},
+		{
+			name: "total with stages",
+			option: vegetaV2.VegetaOption{
+				Rate:     rateOf(intstr.FromInt(10)),
+				RateMode: vegetaV2.TotalRateMode,
+				Workers:  int32Ptr(3),
+			},
+			parallelism: 2,
+			stages:      []vegetaV2.Stage{{Rate: rateOf(intstr.FromInt(10))}},
+			want:        vegetaV2.PodOption{Workers: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attack := &vegetaV2.Attack{
+				Spec: vegetaV2.AttackSpec{
+					Parallelism: tt.parallelism,
+					Option:      tt.option,
+					Stages:      tt.stages,
+				},
+			}
+			if got := buildPodOption(attack); got != tt.want {
+				t.Errorf("buildPodOption() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
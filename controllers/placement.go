@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"context"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	hostnameTopologyKey = "kubernetes.io/hostname"
+	zoneTopologyKey     = v1.LabelZoneFailureDomainStable
+	// betaZoneTopologyKey is the zone label of nodes before Kubernetes 1.17
+	betaZoneTopologyKey = v1.LabelZoneFailureDomain
+)
+
+// buildPlacement returns the affinity and the topology spread constraints of the placement strategy.
+// Each affinity in the pod template replaces that of the strategy, and each topology spread constraint in the pod
+// template replaces that of the strategy with the same topology key.
+func buildPlacement(attack *vegetaV2.Attack, appLabel string) (*v1.Affinity, []v1.TopologySpreadConstraint) {
+	strategy := vegetaV2.PerNodePlacementStrategy
+	var required bool
+	if placement := attack.Spec.Placement; placement != nil {
+		if placement.Strategy != "" {
+			strategy = placement.Strategy
+		}
+		required = placement.Required
+	}
+	selector := &metaV1.LabelSelector{
+		MatchLabels: map[string]string{
+			"app": appLabel,
+		},
+	}
+
+	affinity := &v1.Affinity{}
+	var constraints []v1.TopologySpreadConstraint
+	switch strategy {
+	case vegetaV2.PerNodePlacementStrategy:
+		affinity.PodAntiAffinity = &v1.PodAntiAffinity{}
+		term := v1.PodAffinityTerm{
+			LabelSelector: selector,
+			TopologyKey:   hostnameTopologyKey,
+		}
+		if required {
+			affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = []v1.PodAffinityTerm{term}
+		} else {
+			affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = []v1.WeightedPodAffinityTerm{
+				{
+					Weight:          100,
+					PodAffinityTerm: term,
+				},
+			}
+		}
+	case vegetaV2.PerZonePlacementStrategy:
+		// Topology spread constraints are dropped by the API server without EvenPodsSpread feature gate,
+		// so that the pods are also spread over zones of either label by pod anti-affinity as far as possible.
+		// It is not required even with required, which would allow only one pod in each zone.
+		affinity.PodAntiAffinity = &v1.PodAntiAffinity{}
+		for _, topologyKey := range []string{zoneTopologyKey, betaZoneTopologyKey} {
+			affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+				affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+				v1.WeightedPodAffinityTerm{
+					Weight: 100,
+					PodAffinityTerm: v1.PodAffinityTerm{
+						LabelSelector: selector,
+						TopologyKey:   topologyKey,
+					},
+				},
+			)
+		}
+		whenUnsatisfiable := v1.ScheduleAnyway
+		if required {
+			whenUnsatisfiable = v1.DoNotSchedule
+		}
+		constraints = []v1.TopologySpreadConstraint{
+			{
+				MaxSkew:           1,
+				TopologyKey:       zoneTopologyKey,
+				WhenUnsatisfiable: whenUnsatisfiable,
+				LabelSelector:     selector,
+			},
+			// The pods in each zone are spread over nodes as far as possible as well as PerNode
+			{
+				MaxSkew:           1,
+				TopologyKey:       hostnameTopologyKey,
+				WhenUnsatisfiable: v1.ScheduleAnyway,
+				LabelSelector:     selector,
+			},
+		}
+	case vegetaV2.PackedPlacementStrategy:
+		affinity.PodAffinity = &v1.PodAffinity{}
+		term := v1.PodAffinityTerm{
+			LabelSelector: selector,
+			TopologyKey:   hostnameTopologyKey,
+		}
+		if required {
+			affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution = []v1.PodAffinityTerm{term}
+		} else {
+			affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution = []v1.WeightedPodAffinityTerm{
+				{
+					Weight:          100,
+					PodAffinityTerm: term,
+				},
+			}
+		}
+	}
+
+	template := attack.Spec.Template.Spec
+	if template.Affinity != nil {
+		if template.Affinity.NodeAffinity != nil {
+			affinity.NodeAffinity = template.Affinity.NodeAffinity
+		}
+		if template.Affinity.PodAffinity != nil {
+			affinity.PodAffinity = template.Affinity.PodAffinity
+		}
+		if template.Affinity.PodAntiAffinity != nil {
+			affinity.PodAntiAffinity = template.Affinity.PodAntiAffinity
+		}
+	}
+	if affinity.NodeAffinity == nil && affinity.PodAffinity == nil && affinity.PodAntiAffinity == nil {
+		affinity = nil
+	}
+
+	topologyKeys := map[string]struct{}{}
+	for _, constraint := range template.TopologySpreadConstraints {
+		topologyKeys[constraint.TopologyKey] = struct{}{}
+	}
+	merged := make([]v1.TopologySpreadConstraint, 0, len(constraints)+len(template.TopologySpreadConstraints))
+	for _, constraint := range constraints {
+		if _, ok := topologyKeys[constraint.TopologyKey]; !ok {
+			merged = append(merged, constraint)
+		}
+	}
+	merged = append(merged, template.TopologySpreadConstraints...)
+	if len(merged) == 0 {
+		merged = nil
+	}
+	return affinity, merged
+}
+
+// zoneResolver looks up zones of nodes, remembering them during a reconciliation.
+// Nodes are read from the API server directly, since caching every node of the cluster for a few of them
+// would need to list and watch them.
+type zoneResolver struct {
+	reader client.Reader
+	logger logr.Logger
+	zones  map[string]string
+}
+
+func (r *AttackReconciler) newZoneResolver(logger logr.Logger) *zoneResolver {
+	return &zoneResolver{
+		reader: r.APIReader,
+		logger: logger,
+		zones:  map[string]string{},
+	}
+}
+
+// lookup returns the zone of the node, or an empty string when it is unknown.
+// The beta label is also honored, since older nodes have only it.
+func (z *zoneResolver) lookup(ctx context.Context, nodeName string) string {
+	if nodeName == "" {
+		return ""
+	}
+	if zone, ok := z.zones[nodeName]; ok {
+		return zone
+	}
+	var node v1.Node
+	if err := z.reader.Get(ctx, client.ObjectKey{Name: nodeName}, &node); err != nil {
+		// The zone is only informative, so that the status is updated without it
+		if client.IgnoreNotFound(err) != nil {
+			z.logger.Error(err, "unable to get node", "node", nodeName)
+		}
+		z.zones[nodeName] = ""
+		return ""
+	}
+	zone, ok := node.Labels[v1.LabelZoneFailureDomainStable]
+	if !ok {
+		zone = node.Labels[v1.LabelZoneFailureDomain]
+	}
+	z.zones[nodeName] = zone
+	return zone
+}
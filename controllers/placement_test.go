@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"testing"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildPlacement(t *testing.T) {
+	selector := &metaV1.LabelSelector{
+		MatchLabels: map[string]string{
+			"app": "attack",
+		},
+	}
+	term := func(topologyKey string) v1.PodAffinityTerm {
+		return v1.PodAffinityTerm{LabelSelector: selector, TopologyKey: topologyKey}
+	}
+	preferred := func(topologyKeys ...string) []v1.WeightedPodAffinityTerm {
+		var terms []v1.WeightedPodAffinityTerm
+		for _, topologyKey := range topologyKeys {
+			terms = append(terms, v1.WeightedPodAffinityTerm{Weight: 100, PodAffinityTerm: term(topologyKey)})
+		}
+		return terms
+	}
+	constraint := func(topologyKey string, whenUnsatisfiable v1.UnsatisfiableConstraintAction) v1.TopologySpreadConstraint {
+		return v1.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       topologyKey,
+			WhenUnsatisfiable: whenUnsatisfiable,
+			LabelSelector:     selector,
+		}
+	}
+	templateAffinity := &v1.Affinity{
+		PodAntiAffinity: &v1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{term("example.com/rack")},
+		},
+		NodeAffinity: &v1.NodeAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{
+				{
+					Weight: 1,
+					Preference: v1.NodeSelectorTerm{
+						MatchExpressions: []v1.NodeSelectorRequirement{
+							{Key: "example.com/pool", Operator: v1.NodeSelectorOpIn, Values: []string{"attack"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		placement       *vegetaV2.Placement
+		template        vegetaV2.Spec
+		wantAffinity    *v1.Affinity
+		wantConstraints []v1.TopologySpreadConstraint
+	}{
+		{
+			name: "PerNode by default",
+			wantAffinity: &v1.Affinity{
+				PodAntiAffinity: &v1.PodAntiAffinity{
+					PreferredDuringSchedulingIgnoredDuringExecution: preferred(hostnameTopologyKey),
+				},
+			},
+		},
+		{
+			name:      "required PerNode",
+			placement: &vegetaV2.Placement{Strategy: vegetaV2.PerNodePlacementStrategy, Required: true},
+			wantAffinity: &v1.Affinity{
+				PodAntiAffinity: &v1.PodAntiAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{term(hostnameTopologyKey)},
+				},
+			},
+		},
+		{
+			name:      "PerZone",
+			placement: &vegetaV2.Placement{Strategy: vegetaV2.PerZonePlacementStrategy},
+			wantAffinity: &v1.Affinity{
+				PodAntiAffinity: &v1.PodAntiAffinity{
+					PreferredDuringSchedulingIgnoredDuringExecution: preferred(zoneTopologyKey, betaZoneTopologyKey),
+				},
+			},
+			wantConstraints: []v1.TopologySpreadConstraint{
+				constraint(zoneTopologyKey, v1.ScheduleAnyway),
+				constraint(hostnameTopologyKey, v1.ScheduleAnyway),
+			},
+		},
+		{
+			name:      "required PerZone",
+			placement: &vegetaV2.Placement{Strategy: vegetaV2.PerZonePlacementStrategy, Required: true},
+			wantAffinity: &v1.Affinity{
+				PodAntiAffinity: &v1.PodAntiAffinity{
+					PreferredDuringSchedulingIgnoredDuringExecution: preferred(zoneTopologyKey, betaZoneTopologyKey),
+				},
+			},
+			wantConstraints: []v1.TopologySpreadConstraint{
+				constraint(zoneTopologyKey, v1.DoNotSchedule),
+				constraint(hostnameTopologyKey, v1.ScheduleAnyway),
+			},
+		},
+		{
+			name:      "Packed",
+			placement: &vegetaV2.Placement{Strategy: vegetaV2.PackedPlacementStrategy},
+			wantAffinity: &v1.Affinity{
+				PodAffinity: &v1.PodAffinity{
+					PreferredDuringSchedulingIgnoredDuringExecution: preferred(hostnameTopologyKey),
+				},
+			},
+		},
+		{
+			name:      "Custom without the template",
+			placement: &vegetaV2.Placement{Strategy: vegetaV2.CustomPlacementStrategy},
+		},
+		{
+			name:      "Custom with the template",
+			placement: &vegetaV2.Placement{Strategy: vegetaV2.CustomPlacementStrategy},
+			template: vegetaV2.Spec{
+				Affinity:                  templateAffinity,
+				TopologySpreadConstraints: []v1.TopologySpreadConstraint{constraint("example.com/rack", v1.DoNotSchedule)},
+			},
+			wantAffinity:    templateAffinity,
+			wantConstraints: []v1.TopologySpreadConstraint{constraint("example.com/rack", v1.DoNotSchedule)},
+		},
+		{
+			name:      "PerZone overridden by the template",
+			placement: &vegetaV2.Placement{Strategy: vegetaV2.PerZonePlacementStrategy},
+			template: vegetaV2.Spec{
+				Affinity:                  templateAffinity,
+				TopologySpreadConstraints: []v1.TopologySpreadConstraint{constraint(hostnameTopologyKey, v1.DoNotSchedule)},
+			},
+			wantAffinity: templateAffinity,
+			wantConstraints: []v1.TopologySpreadConstraint{
+				constraint(zoneTopologyKey, v1.ScheduleAnyway),
+				constraint(hostnameTopologyKey, v1.DoNotSchedule),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attack := &vegetaV2.Attack{
+				Spec: vegetaV2.AttackSpec{
+					Placement: tt.placement,
+					Template:  vegetaV2.Template{Spec: tt.template},
+				},
+			}
+			affinity, constraints := buildPlacement(attack, "attack")
+			if !equality.Semantic.DeepEqual(affinity, tt.wantAffinity) {
+				t.Errorf("buildPlacement() affinity = %+v, want %+v", affinity, tt.wantAffinity)
+			}
+			if !equality.Semantic.DeepEqual(constraints, tt.wantConstraints) {
+				t.Errorf("buildPlacement() constraints = %+v, want %+v", constraints, tt.wantConstraints)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resolveKeyRef reads the value of the key referenced by either configMapRef or secretRef, which is a string or bytes.
+// It returns nil with the reason and the message set to the condition when the object or the key is missing.
+// subject tells what the key is for in the message, e.g. ` of body "payload.json"`, or is empty.
+func (r *AttackReconciler) resolveKeyRef(ctx context.Context, attack *vegetaV2.Attack, configMapRef *v1.ConfigMapKeySelector, secretRef *v1.SecretKeySelector, subject string, condition *vegetaV2.Condition) (interface{}, error) {
+	if configMapRef != nil {
+		var configMap v1.ConfigMap
+		if err := r.Get(ctx, client.ObjectKey{Name: configMapRef.Name, Namespace: attack.Namespace}, &configMap); errors.IsNotFound(err) {
+			condition.Reason = "ConfigMapNotFound"
+			condition.Message = fmt.Sprintf("ConfigMap %q%s is not found", configMapRef.Name, subject)
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if value, ok := configMap.Data[configMapRef.Key]; ok {
+			return value, nil
+		}
+		if value, ok := configMap.BinaryData[configMapRef.Key]; ok {
+			return value, nil
+		}
+		condition.Reason = "KeyNotFound"
+		condition.Message = fmt.Sprintf("Key %q%s is not found in ConfigMap %q", configMapRef.Key, subject, configMapRef.Name)
+		return nil, nil
+	}
+
+	var secret v1.Secret
+	if err := r.APIReader.Get(ctx, client.ObjectKey{Name: secretRef.Name, Namespace: attack.Namespace}, &secret); errors.IsNotFound(err) {
+		condition.Reason = "SecretNotFound"
+		condition.Message = fmt.Sprintf("Secret %q%s is not found", secretRef.Name, subject)
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	value, ok := secret.Data[secretRef.Key]
+	if !ok {
+		condition.Reason = "KeyNotFound"
+		condition.Message = fmt.Sprintf("Key %q%s is not found in Secret %q", secretRef.Key, subject, secretRef.Name)
+		return nil, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResolveKeyRef(t *testing.T) {
+	client := fake.NewFakeClientWithScheme(
+		scheme.Scheme,
+		&v1.ConfigMap{
+			ObjectMeta: metaV1.ObjectMeta{Name: "payloads", Namespace: "default"},
+			Data:       map[string]string{"payload.json": "{}"},
+			BinaryData: map[string][]byte{"image.png": {0x89}},
+		},
+		&v1.Secret{
+			ObjectMeta: metaV1.ObjectMeta{Name: "credentials", Namespace: "default"},
+			Data:       map[string][]byte{"token": []byte("secret")},
+		},
+	)
+	r := &AttackReconciler{Client: client, APIReader: client}
+	attack := &vegetaV2.Attack{ObjectMeta: metaV1.ObjectMeta{Name: "sample", Namespace: "default"}}
+	configMapRef := func(name, key string) *v1.ConfigMapKeySelector {
+		return &v1.ConfigMapKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: name}, Key: key}
+	}
+	secretRef := func(name, key string) *v1.SecretKeySelector {
+		return &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: name}, Key: key}
+	}
+
+	tests := []struct {
+		name         string
+		configMapRef *v1.ConfigMapKeySelector
+		secretRef    *v1.SecretKeySelector
+		want         interface{}
+		wantReason   string
+		wantMessage  string
+	}{
+		{
+			name:         "key of ConfigMap",
+			configMapRef: configMapRef("payloads", "payload.json"),
+			want:         "{}",
+		},
+		{
+			name:         "binary key of ConfigMap",
+			configMapRef: configMapRef("payloads", "image.png"),
+			want:         []byte{0x89},
+		},
+		{
+			name:      "key of Secret",
+			secretRef: secretRef("credentials", "token"),
+			want:      []byte("secret"),
+		},
+		{
+			name:         "missing ConfigMap",
+			configMapRef: configMapRef("missing", "payload.json"),
+			wantReason:   "ConfigMapNotFound",
+			wantMessage:  `ConfigMap "missing" of body "payload" is not found`,
+		},
+		{
+			name:         "missing key of ConfigMap",
+			configMapRef: configMapRef("payloads", "missing"),
+			wantReason:   "KeyNotFound",
+			wantMessage:  `Key "missing" of body "payload" is not found in ConfigMap "payloads"`,
+		},
+		{
+			name:        "missing Secret",
+			secretRef:   secretRef("missing", "token"),
+			wantReason:  "SecretNotFound",
+			wantMessage: `Secret "missing" of body "payload" is not found`,
+		},
+		{
+			name:        "missing key of Secret",
+			secretRef:   secretRef("credentials", "missing"),
+			wantReason:  "KeyNotFound",
+			wantMessage: `Key "missing" of body "payload" is not found in Secret "credentials"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var condition vegetaV2.Condition
+			got, err := r.resolveKeyRef(context.Background(), attack, tt.configMapRef, tt.secretRef, ` of body "payload"`, &condition)
+			if err != nil {
+				t.Fatalf("resolveKeyRef() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveKeyRef() = %v, want %v", got, tt.want)
+			}
+			if condition.Reason != tt.wantReason || condition.Message != tt.wantMessage {
+				t.Errorf("resolveKeyRef() condition = (%q, %q), want (%q, %q)", condition.Reason, condition.Message, tt.wantReason, tt.wantMessage)
+			}
+		})
+	}
+}
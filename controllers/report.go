@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// vegetaMetrics is the JSON representation of `vegeta report -type json`
+type vegetaMetrics struct {
+	Latencies struct {
+		Total time.Duration `json:"total"`
+		Mean  time.Duration `json:"mean"`
+		P50   time.Duration `json:"50th"`
+		P95   time.Duration `json:"95th"`
+		P99   time.Duration `json:"99th"`
+		Max   time.Duration `json:"max"`
+	} `json:"latencies"`
+	BytesIn struct {
+		Total uint64  `json:"total"`
+		Mean  float64 `json:"mean"`
+	} `json:"bytes_in"`
+	BytesOut struct {
+		Total uint64  `json:"total"`
+		Mean  float64 `json:"mean"`
+	} `json:"bytes_out"`
+	Earliest    time.Time      `json:"earliest"`
+	Latest      time.Time      `json:"latest"`
+	End         time.Time      `json:"end"`
+	Duration    time.Duration  `json:"duration"`
+	Wait        time.Duration  `json:"wait"`
+	Requests    uint64         `json:"requests"`
+	Rate        float64        `json:"rate"`
+	Throughput  float64        `json:"throughput"`
+	Success     float64        `json:"success"`
+	StatusCodes map[string]int `json:"status_codes"`
+	Errors      []string       `json:"errors"`
+}
+
+func parseVegetaMetrics(s string) (*vegetaMetrics, error) {
+	var metrics vegetaMetrics
+	if err := json.Unmarshal([]byte(s), &metrics); err != nil {
+		return nil, err
+	}
+	return &metrics, nil
+}
+
+func (m *vegetaMetrics) toReport() *vegetaV2.Report {
+	report := &vegetaV2.Report{
+		Requests:   int64(m.Requests),
+		Rate:       strconv.FormatFloat(m.Rate, 'f', 2, 64),
+		Throughput: strconv.FormatFloat(m.Throughput, 'f', 2, 64),
+		Success:    strconv.FormatFloat(m.Success, 'f', 4, 64),
+		Duration:   metaV1.Duration{Duration: m.Duration},
+		Wait:       metaV1.Duration{Duration: m.Wait},
+		Latencies: vegetaV2.Latencies{
+			Mean: metaV1.Duration{Duration: m.Latencies.Mean},
+			P50:  metaV1.Duration{Duration: m.Latencies.P50},
+			P95:  metaV1.Duration{Duration: m.Latencies.P95},
+			P99:  metaV1.Duration{Duration: m.Latencies.P99},
+			Max:  metaV1.Duration{Duration: m.Latencies.Max},
+		},
+		BytesIn: vegetaV2.Bytes{
+			Total: int64(m.BytesIn.Total),
+			Mean:  strconv.FormatFloat(m.BytesIn.Mean, 'f', 2, 64),
+		},
+		BytesOut: vegetaV2.Bytes{
+			Total: int64(m.BytesOut.Total),
+			Mean:  strconv.FormatFloat(m.BytesOut.Mean, 'f', 2, 64),
+		},
+		Errors: m.Errors,
+	}
+	if len(m.StatusCodes) > 0 {
+		report.StatusCodes = make(map[string]int64, len(m.StatusCodes))
+		for code, count := range m.StatusCodes {
+			report.StatusCodes[code] = int64(count)
+		}
+	}
+	return report
+}
+
+// collectPodMetrics reads the JSON report written to the termination message of the vegeta container
+func collectPodMetrics(status *vegetaV2.AttackPodStatus, containerStatuses []coreV1.ContainerStatus) *vegetaMetrics {
+	for _, containerStatus := range containerStatuses {
+		if containerStatus.Name != "vegeta" || containerStatus.State.Terminated == nil {
+			continue
+		}
+		if containerStatus.State.Terminated.Message == "" {
+			status.Message = fmt.Sprintf("vegeta container exited with %d without report", containerStatus.State.Terminated.ExitCode)
+			return nil
+		}
+		if strings.HasPrefix(containerStatus.State.Terminated.Message, unsupportedOptionsMessage) {
+			status.Message = strings.TrimSpace(containerStatus.State.Terminated.Message)
+			return nil
+		}
+		metrics, err := parseVegetaMetrics(containerStatus.State.Terminated.Message)
+		if err != nil {
+			status.Message = fmt.Sprintf("failed to parse report: %s", err)
+			return nil
+		}
+		status.Report = metrics.toReport()
+		return metrics
+	}
+	return nil
+}
+
+// findUnsupportedOptions returns the flags which the vegeta container reported that the image does not support,
+// and whether the container has got over the probe of flags
+func findUnsupportedOptions(containerStatuses []coreV1.ContainerStatus) (string, bool) {
+	for _, containerStatus := range containerStatuses {
+		if containerStatus.Name != "vegeta" {
+			continue
+		}
+		if containerStatus.State.Terminated != nil {
+			message := containerStatus.State.Terminated.Message
+			if strings.HasPrefix(message, unsupportedOptionsMessage) {
+				return strings.TrimSpace(strings.TrimPrefix(message, unsupportedOptionsMessage)), true
+			}
+			return "", true
+		}
+		return "", containerStatus.State.Running != nil
+	}
+	return "", false
+}
@@ -0,0 +1,508 @@
+package controllers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	resultsContainerName = "results"
+	// stripBodiesProgram is the awk program which empties the response bodies of CSV results streamed to the controller,
+	// which never reads them, so that the log does not grow with the responses.
+	// The fields before the body are numbers except for the error, which is quoted when it has commas or quotes.
+	// Each line is flushed for the live metrics.
+	stripBodiesProgram = `{ if (match($0, /^-?[0-9]+,[0-9]+,-?[0-9]+,[0-9]+,[0-9]+,("([^"]|"")*"|[^,"]*),/)) { rest = substr($0, RLENGTH + 1); sub(/^[^,]*/, "", rest); $0 = substr($0, 1, RLENGTH) rest } print; fflush() }`
+	// countResultsProgram is the awk program which passes the results through to the log of results container,
+	// and writes the number of them to its termination message
+	countResultsProgram = `{ print; fflush() } END { printf "%d", NR > "/dev/termination-log" }`
+	// artifactHeader begins the line which precedes each report generated by vegeta in the log of results container,
+	// followed by the key of the report.
+	// The reports follow the results, and each of them ends with an extra line break.
+	artifactHeader = "#artifact "
+)
+
+// result is a vegeta result decoded from `vegeta encode -to csv`
+// More info: https://github.com/tsenart/vegeta#encode-command
+type result struct {
+	Timestamp time.Time
+	Code      uint16
+	Latency   time.Duration
+	BytesOut  uint64
+	BytesIn   uint64
+	Error     string
+	Attack    string
+}
+
+func (r *result) End() time.Time {
+	return r.Timestamp.Add(r.Latency)
+}
+
+// decodeCSVResults decodes results until EOF.
+// Columns after the name of attack are ignored, since they differ between vegeta versions.
+func decodeCSVResults(reader io.Reader, fn func(*result)) error {
+	decoder := csv.NewReader(reader)
+	decoder.FieldsPerRecord = -1
+	decoder.ReuseRecord = true
+	for {
+		record, err := decoder.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(record) < 6 {
+			return fmt.Errorf("invalid result record with %d fields", len(record))
+		}
+
+		timestamp, err := strconv.ParseInt(record[0], 10, 64)
+		if err != nil {
+			return err
+		}
+		code, err := strconv.ParseUint(record[1], 10, 16)
+		if err != nil {
+			return err
+		}
+		latency, err := strconv.ParseInt(record[2], 10, 64)
+		if err != nil {
+			return err
+		}
+		bytesOut, err := strconv.ParseUint(record[3], 10, 64)
+		if err != nil {
+			return err
+		}
+		bytesIn, err := strconv.ParseUint(record[4], 10, 64)
+		if err != nil {
+			return err
+		}
+
+		r := &result{
+			Timestamp: time.Unix(0, timestamp),
+			Code:      uint16(code),
+			Latency:   time.Duration(latency),
+			BytesOut:  bytesOut,
+			BytesIn:   bytesIn,
+			Error:     record[5],
+		}
+		// The name of attack follows the base64 encoded body
+		if len(record) > 7 {
+			r.Attack = record[7]
+		}
+		fn(r)
+	}
+}
+
+// decodeResultsLog decodes the results in the log of results container until the reports following them,
+// and returns the reports keyed by their keys
+func decodeResultsLog(reader io.Reader, fn func(*result)) (map[string]string, error) {
+	section := &resultsSection{reader: bufio.NewReader(reader)}
+	if err := decodeCSVResults(section, fn); err != nil {
+		return nil, err
+	}
+
+	artifacts := map[string]string{}
+	header := section.header
+	for header != "" {
+		var artifact bytes.Buffer
+		next := ""
+		for {
+			line, err := section.reader.ReadString('\n')
+			if strings.HasPrefix(line, artifactHeader) {
+				next = line
+				break
+			}
+			artifact.WriteString(line)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		artifacts[strings.TrimSpace(strings.TrimPrefix(header, artifactHeader))] = strings.TrimSuffix(artifact.String(), "\n")
+		header = next
+	}
+	return artifacts, nil
+}
+
+// resultsSection reads the lines of the results until the header of the first report, which is kept in header
+type resultsSection struct {
+	reader  *bufio.Reader
+	header  string
+	pending string
+}
+
+func (s *resultsSection) Read(p []byte) (int, error) {
+	if s.pending == "" {
+		if s.header != "" {
+			return 0, io.EOF
+		}
+		line, err := s.reader.ReadString('\n')
+		if strings.HasPrefix(line, artifactHeader) {
+			s.header = line
+			return 0, io.EOF
+		}
+		if line == "" {
+			return 0, err
+		}
+		s.pending = line
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// countWrittenResults returns the number of results which results container wrote to its log,
+// and false when it is unknown, e.g. for the pods created before it was counted
+func countWrittenResults(pod *coreV1.Pod) (uint64, bool) {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name != resultsContainerName || containerStatus.State.Terminated == nil {
+			continue
+		}
+		count, err := strconv.ParseUint(strings.TrimSpace(containerStatus.State.Terminated.Message), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return count, true
+	}
+	return 0, false
+}
+
+// buildResultsCollectedCondition returns whether all results written by the attack pods have been read,
+// which is False when some of them have been lost by log rotation of kubelet
+func buildResultsCollectedCondition(attack *vegetaV2.Attack, truncated []string) vegetaV2.Condition {
+	condition := vegetaV2.Condition{
+		Type:               vegetaV2.AttackResultsCollected,
+		Status:             metaV1.ConditionTrue,
+		ObservedGeneration: attack.Generation,
+		Reason:             "ResultsCollected",
+	}
+	if len(truncated) > 0 {
+		sorted := append([]string(nil), truncated...)
+		sort.Strings(sorted)
+		condition.Status = metaV1.ConditionFalse
+		condition.Reason = "ResultsTruncated"
+		condition.Message = fmt.Sprintf("Some results of pods %s were lost by rotation of their logs, so that the report of the attack is not computed", strings.Join(sorted, ", "))
+	}
+	return condition
+}
+
+// maxErrorSet limits the number of distinct errors kept, since errors may vary without bound, e.g. by addresses
+const maxErrorSet = 100
+
+// resultMetrics accumulates results to compute the metrics.
+// Unlike vegeta reports, it can be merged with others without loss, since every latency is kept instead of being
+// summarized. The other fields of raw results are not kept, so that it takes 8 bytes for each result.
+type resultMetrics struct {
+	requests     uint64
+	successes    uint64
+	failures     uint64
+	latencies    latencySamples
+	latencyTotal time.Duration
+	latencyMin   time.Duration
+	latencyMax   time.Duration
+	bytesIn      uint64
+	bytesOut     uint64
+	earliest     time.Time
+	latest       time.Time
+	end          time.Time
+	statusCodes  map[string]int
+	errors       map[string]struct{}
+}
+
+func newResultMetrics() *resultMetrics {
+	return &resultMetrics{
+		statusCodes: map[string]int{},
+		errors:      map[string]struct{}{},
+	}
+}
+
+func (m *resultMetrics) add(r *result) {
+	if m.requests == 0 || r.Latency < m.latencyMin {
+		m.latencyMin = r.Latency
+	}
+	if r.Latency > m.latencyMax {
+		m.latencyMax = r.Latency
+	}
+	m.requests++
+	// Same as the definition of success in vegeta
+	if r.Code >= 200 && r.Code < 400 {
+		m.successes++
+	}
+	// Same as the definition of errors in the error set of vegeta
+	if r.Error != "" {
+		m.failures++
+	}
+	m.latencies.observe(r.Latency)
+	m.latencyTotal += r.Latency
+	m.bytesIn += r.BytesIn
+	m.bytesOut += r.BytesOut
+	if m.earliest.IsZero() || r.Timestamp.Before(m.earliest) {
+		m.earliest = r.Timestamp
+	}
+	if r.Timestamp.After(m.latest) {
+		m.latest = r.Timestamp
+	}
+	if r.End().After(m.end) {
+		m.end = r.End()
+	}
+	m.statusCodes[strconv.Itoa(int(r.Code))]++
+	if _, ok := m.errors[r.Error]; r.Error != "" && !ok && len(m.errors) < maxErrorSet {
+		m.errors[r.Error] = struct{}{}
+	}
+}
+
+func (m *resultMetrics) merge(other *resultMetrics) {
+	if other.requests == 0 {
+		return
+	}
+	if m.requests == 0 || other.latencyMin < m.latencyMin {
+		m.latencyMin = other.latencyMin
+	}
+	if other.latencyMax > m.latencyMax {
+		m.latencyMax = other.latencyMax
+	}
+	m.requests += other.requests
+	m.successes += other.successes
+	m.failures += other.failures
+	m.latencies.merge(&other.latencies)
+	m.latencyTotal += other.latencyTotal
+	m.bytesIn += other.bytesIn
+	m.bytesOut += other.bytesOut
+	if m.earliest.IsZero() || other.earliest.Before(m.earliest) {
+		m.earliest = other.earliest
+	}
+	if other.latest.After(m.latest) {
+		m.latest = other.latest
+	}
+	if other.end.After(m.end) {
+		m.end = other.end
+	}
+	for code, count := range other.statusCodes {
+		m.statusCodes[code] += count
+	}
+	for e := range other.errors {
+		if len(m.errors) >= maxErrorSet {
+			break
+		}
+		m.errors[e] = struct{}{}
+	}
+}
+
+// percentile returns the exact nearest-rank percentile of the latencies
+func (m *resultMetrics) percentile(p float64) time.Duration {
+	return m.latencies.percentile(p)
+}
+
+// vegetaMetrics computes the metrics in the same manner as vegeta, except that percentiles are merged across pods
+func (m *resultMetrics) vegetaMetrics() *vegetaMetrics {
+	metrics := &vegetaMetrics{
+		Requests:    m.requests,
+		Earliest:    m.earliest,
+		Latest:      m.latest,
+		End:         m.end,
+		Duration:    m.latest.Sub(m.earliest),
+		Wait:        m.end.Sub(m.latest),
+		StatusCodes: make(map[string]int, len(m.statusCodes)),
+	}
+	for code, count := range m.statusCodes {
+		metrics.StatusCodes[code] = count
+	}
+	for e := range m.errors {
+		metrics.Errors = append(metrics.Errors, e)
+	}
+	sort.Strings(metrics.Errors)
+
+	if m.requests == 0 {
+		return metrics
+	}
+
+	metrics.Success = float64(m.successes) / float64(m.requests)
+	if seconds := metrics.Duration.Seconds(); seconds > 0 {
+		metrics.Rate = float64(m.requests) / seconds
+	}
+	if seconds := (metrics.Duration + metrics.Wait).Seconds(); seconds > 0 {
+		metrics.Throughput = float64(m.successes) / seconds
+	}
+	metrics.BytesIn.Total = m.bytesIn
+	metrics.BytesIn.Mean = float64(m.bytesIn) / float64(m.requests)
+	metrics.BytesOut.Total = m.bytesOut
+	metrics.BytesOut.Mean = float64(m.bytesOut) / float64(m.requests)
+
+	metrics.Latencies.Total = m.latencyTotal
+	metrics.Latencies.Mean = m.latencyTotal / time.Duration(m.requests)
+	metrics.Latencies.P50 = m.percentile(0.50)
+	metrics.Latencies.P95 = m.percentile(0.95)
+	metrics.Latencies.P99 = m.percentile(0.99)
+	metrics.Latencies.Max = m.latencyMax
+
+	return metrics
+}
+
+// latencySamples keeps every latency, so that percentiles are exact however the results are split across pods
+type latencySamples struct {
+	values []time.Duration
+	// sorted is set while the values are in ascending order, which is kept until another latency is added
+	sorted bool
+}
+
+func (s *latencySamples) observe(latency time.Duration) {
+	s.values = append(s.values, latency)
+	s.sorted = false
+}
+
+func (s *latencySamples) merge(other *latencySamples) {
+	if len(other.values) == 0 {
+		return
+	}
+	s.values = append(s.values, other.values...)
+	s.sorted = false
+}
+
+func (s *latencySamples) sort() {
+	if s.sorted {
+		return
+	}
+	sort.Slice(s.values, func(i, j int) bool {
+		return s.values[i] < s.values[j]
+	})
+	s.sorted = true
+}
+
+// percentile returns the nearest-rank percentile, or zero without latencies
+func (s *latencySamples) percentile(p float64) time.Duration {
+	if len(s.values) == 0 {
+		return 0
+	}
+	s.sort()
+	rank := int(math.Ceil(p * float64(len(s.values))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(s.values) {
+		rank = len(s.values)
+	}
+	return s.values[rank-1]
+}
+
+// podResults keeps the results of a pod grouped by the name of attack, which is the stage
+type podResults struct {
+	stages map[string]*resultMetrics
+	// artifacts are the reports which vegeta generated from the results of the pod, keyed by the keys of the reports
+	artifacts map[string]string
+	// totals caches the results of all stages until another result is added, not to copy the latencies every time
+	totals *resultMetrics
+}
+
+func newPodResults() *podResults {
+	return &podResults{
+		stages: map[string]*resultMetrics{},
+	}
+}
+
+func (p *podResults) add(r *result) {
+	metrics, ok := p.stages[r.Attack]
+	if !ok {
+		metrics = newResultMetrics()
+		p.stages[r.Attack] = metrics
+	}
+	metrics.add(r)
+	p.totals = nil
+}
+
+// total returns the results of all stages, which must not be modified
+func (p *podResults) total() *resultMetrics {
+	if p.totals != nil {
+		return p.totals
+	}
+	total := newResultMetrics()
+	for _, metrics := range p.stages {
+		total.merge(metrics)
+	}
+	p.totals = total
+	return total
+}
+
+// resultStore keeps the results read from finished attack pods, which have not been followed to the end by liveStreamer.
+// Results of a finished pod never change, so they are read only once.
+type resultStore struct {
+	mu      sync.Mutex
+	attacks map[types.NamespacedName]map[types.UID]*podResults
+}
+
+func newResultStore() *resultStore {
+	return &resultStore{
+		attacks: map[types.NamespacedName]map[types.UID]*podResults{},
+	}
+}
+
+// load returns the results of the pod, reading them from the log of results container at first
+func (s *resultStore) load(clientset kubernetes.Interface, attack types.NamespacedName, pod *coreV1.Pod) (*podResults, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pods, ok := s.attacks[attack]
+	if !ok {
+		pods = map[types.UID]*podResults{}
+		s.attacks[attack] = pods
+	}
+	if results, ok := pods[pod.UID]; ok {
+		return results, nil
+	}
+
+	stream, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &coreV1.PodLogOptions{
+		Container: resultsContainerName,
+	}).Stream()
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	results := newPodResults()
+	artifacts, err := decodeResultsLog(stream, results.add)
+	if err != nil {
+		return nil, err
+	}
+	results.artifacts = artifacts
+	pods[pod.UID] = results
+	return results, nil
+}
+
+// retain forgets the results of pods that no longer exist
+func (s *resultStore) retain(attack types.NamespacedName, pods []coreV1.Pod) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := make(map[types.UID]struct{}, len(pods))
+	for _, pod := range pods {
+		existing[pod.UID] = struct{}{}
+	}
+	for uid := range s.attacks[attack] {
+		if _, ok := existing[uid]; !ok {
+			delete(s.attacks[attack], uid)
+		}
+	}
+}
+
+func (s *resultStore) forget(attack types.NamespacedName) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attacks, attack)
+}
@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+)
+
+func TestDecodeCSVResults(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []result
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			input: "",
+		},
+		{
+			name:  "without the name of attack",
+			input: "1500000000000000000,200,1000000,10,20,,Ym9keQ==\n",
+			want: []result{
+				{Timestamp: time.Unix(0, 1500000000000000000), Code: 200, Latency: time.Millisecond, BytesOut: 10, BytesIn: 20},
+			},
+		},
+		{
+			name: "with the name of attack and the extra columns",
+			input: "1500000000000000000,500,2000000,0,0,\"dial tcp: refused, again\",,stage-1,3,GET,http://example.com\n" +
+				"1500000001000000000,0,0,0,0,timeout,,stage-2\n",
+			want: []result{
+				{Timestamp: time.Unix(0, 1500000000000000000), Code: 500, Latency: 2 * time.Millisecond, Error: "dial tcp: refused, again", Attack: "stage-1"},
+				{Timestamp: time.Unix(0, 1500000001000000000), Error: "timeout", Attack: "stage-2"},
+			},
+		},
+		{
+			name:    "too few fields",
+			input:   "1500000000000000000,200,1000000,10,20\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid status code",
+			input:   "1500000000000000000,70000,1000000,10,20,,\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []result
+			err := decodeCSVResults(strings.NewReader(tt.input), func(r *result) {
+				got = append(got, *r)
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeCSVResults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("decodeCSVResults() decoded %d results, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !got[i].Timestamp.Equal(tt.want[i].Timestamp) {
+					t.Errorf("results[%d].Timestamp = %v, want %v", i, got[i].Timestamp, tt.want[i].Timestamp)
+				}
+				got[i].Timestamp = tt.want[i].Timestamp
+				if got[i] != tt.want[i] {
+					t.Errorf("results[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLatencySamplesPercentile(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	var latencies []time.Duration
+	var samples latencySamples
+	for i := 0; i < 10000; i++ {
+		latency := time.Duration(random.ExpFloat64() * float64(50*time.Millisecond))
+		latencies = append(latencies, latency)
+		samples.observe(latency)
+	}
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+
+	for _, p := range []float64{0, 0.01, 0.5, 0.95, 0.99, 1} {
+		rank := int(math.Ceil(p * float64(len(latencies))))
+		if rank < 1 {
+			rank = 1
+		}
+		if got, want := samples.percentile(p), latencies[rank-1]; got != want {
+			t.Errorf("percentile(%v) = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestLatencySamplesPercentileEmpty(t *testing.T) {
+	var samples latencySamples
+	if got := samples.percentile(0.99); got != 0 {
+		t.Errorf("percentile(0.99) = %v, want 0", got)
+	}
+}
+
+func TestDecodeResultsLog(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		wantResults   int
+		wantArtifacts map[string]string
+		wantErr       bool
+	}{
+		{
+			name:          "without artifacts",
+			input:         "1500000000000000000,200,1000000,10,20,,\n1500000001000000000,200,1000000,10,20,,\n",
+			wantResults:   2,
+			wantArtifacts: map[string]string{},
+		},
+		{
+			name: "with artifacts",
+			input: "1500000000000000000,200,1000000,10,20,,\n" +
+				"#artifact hist.txt\nBucket  #  %\n[0s, +Inf]  1  100.00%\n\n" +
+				"#artifact plot.html\n<html>\n</html>\n",
+			wantResults: 1,
+			wantArtifacts: map[string]string{
+				"hist.txt":  "Bucket  #  %\n[0s, +Inf]  1  100.00%\n",
+				"plot.html": "<html>\n</html>",
+			},
+		},
+		{
+			name:          "only artifacts",
+			input:         "#artifact hdrplot.txt\n\n",
+			wantArtifacts: map[string]string{"hdrplot.txt": ""},
+		},
+		{
+			name:    "broken results",
+			input:   "1500000000000000000,200\n#artifact hist.txt\n\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var results int
+			artifacts, err := decodeResultsLog(strings.NewReader(tt.input), func(r *result) {
+				results++
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeResultsLog() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if results != tt.wantResults {
+				t.Errorf("decodeResultsLog() decoded %d results, want %d", results, tt.wantResults)
+			}
+			if !reflect.DeepEqual(artifacts, tt.wantArtifacts) {
+				t.Errorf("decodeResultsLog() artifacts = %q, want %q", artifacts, tt.wantArtifacts)
+			}
+		})
+	}
+}
+
+func TestResultMetricsMerge(t *testing.T) {
+	start := time.Unix(1500000000, 0)
+	results := []result{
+		{Timestamp: start, Code: 200, Latency: 10 * time.Millisecond, BytesIn: 100, BytesOut: 10},
+		{Timestamp: start.Add(time.Second), Code: 500, Latency: 30 * time.Millisecond, BytesIn: 50, BytesOut: 10, Error: "500 Internal Server Error"},
+		{Timestamp: start.Add(2 * time.Second), Code: 200, Latency: 20 * time.Millisecond, BytesIn: 100, BytesOut: 10},
+		{Timestamp: start.Add(3 * time.Second), Code: 0, Latency: 5 * time.Millisecond, Error: "timeout"},
+	}
+
+	whole := newResultMetrics()
+	for i := range results {
+		whole.add(&results[i])
+	}
+
+	// Split the results across pods unevenly, including a pod without results
+	parts := []*resultMetrics{newResultMetrics(), newResultMetrics(), newResultMetrics()}
+	for i := range results {
+		parts[i%2].add(&results[i])
+	}
+	merged := newResultMetrics()
+	for _, part := range parts {
+		merged.merge(part)
+	}
+
+	want := whole.vegetaMetrics()
+	got := merged.vegetaMetrics()
+	if got.Requests != want.Requests || got.Success != want.Success || got.Rate != want.Rate || got.Throughput != want.Throughput {
+		t.Errorf("merged metrics = %+v, want %+v", got, want)
+	}
+	if got.Latencies != want.Latencies {
+		t.Errorf("merged latencies = %+v, want %+v", got.Latencies, want.Latencies)
+	}
+	if got.BytesIn != want.BytesIn || got.BytesOut != want.BytesOut {
+		t.Errorf("merged bytes = %+v %+v, want %+v %+v", got.BytesIn, got.BytesOut, want.BytesIn, want.BytesOut)
+	}
+	if !got.Earliest.Equal(want.Earliest) || !got.Latest.Equal(want.Latest) || !got.End.Equal(want.End) {
+		t.Errorf("merged times = %v %v %v, want %v %v %v", got.Earliest, got.Latest, got.End, want.Earliest, want.Latest, want.End)
+	}
+	if strings.Join(got.Errors, "\n") != strings.Join(want.Errors, "\n") {
+		t.Errorf("merged errors = %v, want %v", got.Errors, want.Errors)
+	}
+	if len(got.StatusCodes) != len(want.StatusCodes) {
+		t.Errorf("merged status codes = %v, want %v", got.StatusCodes, want.StatusCodes)
+	}
+	for code, count := range want.StatusCodes {
+		if got.StatusCodes[code] != count {
+			t.Errorf("merged status codes = %v, want %v", got.StatusCodes, want.StatusCodes)
+		}
+	}
+	if got.Latencies.Max != 30*time.Millisecond {
+		t.Errorf("merged max latency = %v, want %v", got.Latencies.Max, 30*time.Millisecond)
+	}
+	if got.Success != 0.5 {
+		t.Errorf("merged success = %v, want 0.5", got.Success)
+	}
+}
+
+func TestCountWrittenResults(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		running   bool
+		want      uint64
+		wantKnown bool
+	}{
+		{
+			name:      "counted",
+			message:   "1200",
+			want:      1200,
+			wantKnown: true,
+		},
+		{
+			name:    "created before counted",
+			message: "",
+		},
+		{
+			name:    "running",
+			running: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := coreV1.ContainerState{Terminated: &coreV1.ContainerStateTerminated{Message: tt.message}}
+			if tt.running {
+				state = coreV1.ContainerState{Running: &coreV1.ContainerStateRunning{}}
+			}
+			pod := &coreV1.Pod{
+				Status: coreV1.PodStatus{
+					ContainerStatuses: []coreV1.ContainerStatus{
+						{Name: "vegeta", State: coreV1.ContainerState{Terminated: &coreV1.ContainerStateTerminated{Message: "{}"}}},
+						{Name: resultsContainerName, State: state},
+					},
+				},
+			}
+			got, known := countWrittenResults(pod)
+			if got != tt.want || known != tt.wantKnown {
+				t.Errorf("countWrittenResults() = (%d, %v), want (%d, %v)", got, known, tt.want, tt.wantKnown)
+			}
+		})
+	}
+}
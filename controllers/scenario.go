@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"context"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// Index of the config maps referenced by scenarioFrom, bodies and tls, whose changes are applied to the attack
+	referencedConfigMapKey = ".spec.referencedConfigMaps"
+	// Index of the secrets referenced by scenarioFrom, bodies and tls, whose changes are applied to the attack
+	referencedSecretKey = ".spec.referencedSecrets"
+)
+
+// reconcileScenario makes the scenario available to the attack pods.
+// It returns the hash of the scenario, which changes the pod template when the scenario changes.
+func (r *AttackReconciler) reconcileScenario(ctx context.Context, logger logr.Logger, attack *vegetaV2.Attack) (string, vegetaV2.Condition, error) {
+	condition := vegetaV2.Condition{
+		Type:               vegetaV2.AttackScenarioAvailable,
+		Status:             metaV1.ConditionFalse,
+		ObservedGeneration: attack.Generation,
+	}
+
+	source := attack.Spec.ScenarioFrom
+	switch {
+	case source == nil:
+		if attack.Spec.Scenario == "" {
+			condition.Reason = "ScenarioNotSpecified"
+			condition.Message = "Either scenario or scenarioFrom must be specified"
+			return "", condition, nil
+		}
+
+		configMap := r.buildScenarioConfigMap(attack)
+		if err := r.reconcileConfigMap(ctx, logger, attack, configMap, "scenario config map"); err != nil {
+			return "", condition, err
+		}
+		condition.Status = metaV1.ConditionTrue
+		condition.Reason = "Inline"
+		return configMap.Annotations[specHashAnnotation], condition, nil
+	case (source.ConfigMapKeyRef == nil) != (source.SecretKeyRef == nil):
+		value, err := r.resolveKeyRef(ctx, attack, source.ConfigMapKeyRef, source.SecretKeyRef, "", &condition)
+		if value == nil || err != nil {
+			return "", condition, err
+		}
+		condition.Status = metaV1.ConditionTrue
+		condition.Reason = "ConfigMapKeyRef"
+		if source.SecretKeyRef != nil {
+			condition.Reason = "SecretKeyRef"
+		}
+		hash, err := computeHash(value)
+		return hash, condition, err
+	default:
+		condition.Reason = "InvalidScenarioSource"
+		condition.Message = "Exactly one of configMapKeyRef and secretKeyRef must be specified in scenarioFrom"
+		return "", condition, nil
+	}
+}
+
+// buildScenarioVolumeSource returns the volume which has the scenario at "scenario" path
+func buildScenarioVolumeSource(attack *vegetaV2.Attack) v1.VolumeSource {
+	source := attack.Spec.ScenarioFrom
+	switch {
+	case source != nil && source.ConfigMapKeyRef != nil:
+		return v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: source.ConfigMapKeyRef.LocalObjectReference,
+				Items: []v1.KeyToPath{
+					{
+						Key:  source.ConfigMapKeyRef.Key,
+						Path: "scenario",
+					},
+				},
+			},
+		}
+	case source != nil && source.SecretKeyRef != nil:
+		return v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: source.SecretKeyRef.Name,
+				Items: []v1.KeyToPath{
+					{
+						Key:  source.SecretKeyRef.Key,
+						Path: "scenario",
+					},
+				},
+			},
+		}
+	default:
+		return v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{
+					Name: attack.Name + "-scenario",
+				},
+			},
+		}
+	}
+}
+
+func indexReferencedConfigMaps(rawObj runtime.Object) []string {
+	attack := rawObj.(*vegetaV2.Attack)
+	var names []string
+	if attack.Spec.ScenarioFrom != nil && attack.Spec.ScenarioFrom.ConfigMapKeyRef != nil {
+		names = append(names, attack.Spec.ScenarioFrom.ConfigMapKeyRef.Name)
+	}
+	for _, body := range attack.Spec.Bodies {
+		if body.ValueFrom != nil && body.ValueFrom.ConfigMapKeyRef != nil {
+			names = append(names, body.ValueFrom.ConfigMapKeyRef.Name)
+		}
+	}
+	if tls := attack.Spec.TLS; tls != nil && tls.RootCAs != nil && tls.RootCAs.ConfigMapKeyRef != nil {
+		names = append(names, tls.RootCAs.ConfigMapKeyRef.Name)
+	}
+	return names
+}
+
+func indexReferencedSecrets(rawObj runtime.Object) []string {
+	return referencedSecrets(rawObj.(*vegetaV2.Attack))
+}
+
+// referencedSecrets returns the names of Secrets whose changes are applied to the attack
+func referencedSecrets(attack *vegetaV2.Attack) []string {
+	var names []string
+	if attack.Spec.ScenarioFrom != nil && attack.Spec.ScenarioFrom.SecretKeyRef != nil {
+		names = append(names, attack.Spec.ScenarioFrom.SecretKeyRef.Name)
+	}
+	for _, body := range attack.Spec.Bodies {
+		if body.ValueFrom != nil && body.ValueFrom.SecretKeyRef != nil {
+			names = append(names, body.ValueFrom.SecretKeyRef.Name)
+		}
+	}
+	if tls := attack.Spec.TLS; tls != nil {
+		if tls.ClientCertSecretRef != nil {
+			names = append(names, tls.ClientCertSecretRef.Name)
+		}
+		if tls.RootCAs != nil && tls.RootCAs.SecretKeyRef != nil {
+			names = append(names, tls.RootCAs.SecretKeyRef.Name)
+		}
+	}
+	return names
+}
+
+// referencingAttacks returns the function to enqueue attacks that reference the object by the index
+func (r *AttackReconciler) referencingAttacks(indexKey string) handler.ToRequestsFunc {
+	return func(object handler.MapObject) []reconcile.Request {
+		var attacks vegetaV2.AttackList
+		if err := r.List(
+			context.Background(),
+			&attacks,
+			client.InNamespace(object.Meta.GetNamespace()),
+			client.MatchingFields{indexKey: object.Meta.GetName()},
+		); err != nil {
+			r.Log.Error(err, "unable to list attacks", "index", indexKey, "name", object.Meta.GetName())
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(attacks.Items))
+		for _, attack := range attacks.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      attack.Name,
+					Namespace: attack.Namespace,
+				},
+			})
+		}
+		return requests
+	}
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	batchV1 "k8s.io/api/batch/v1"
+)
+
+const stopReasonDeadlineExceeded = "DeadlineExceeded"
+
+// isAttackScheduled returns true when the job of the attack must not be created yet
+func isAttackScheduled(attack *vegetaV2.Attack) bool {
+	return attack.Spec.StartAt != nil && time.Now().Before(attack.Spec.StartAt.Time)
+}
+
+func isDeadlineExceeded(attack *vegetaV2.Attack) bool {
+	return attack.Spec.Deadline != nil && !time.Now().Before(attack.Spec.Deadline.Time)
+}
+
+// untilSchedule returns how long to wait until startAt of the job which has not been created,
+// or until the deadline of the running job, or zero when there is nothing to wait for.
+// Nothing else triggers reconciliation at these times.
+func untilSchedule(attack *vegetaV2.Attack, job *batchV1.Job) time.Duration {
+	if job.UID == "" {
+		if attack.Spec.StartAt == nil || attack.Spec.Suspend {
+			return 0
+		}
+		return time.Until(attack.Spec.StartAt.Time)
+	}
+	if attack.Spec.Deadline == nil || isJobFinished(job) || isJobStopped(job) {
+		return 0
+	}
+	return time.Until(attack.Spec.Deadline.Time)
+}
+
+// buildDeadlineCondition returns the shell condition which holds after the deadline, or an empty string without it.
+// The pods stop by themselves at the deadline, since the stop annotation takes a while to reach them.
+func buildDeadlineCondition(attack *vegetaV2.Attack) string {
+	if attack.Spec.Deadline == nil {
+		return ""
+	}
+	return fmt.Sprintf(`[ "$(date +%%s)" -ge %d ]`, attack.Spec.Deadline.Unix())
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+)
+
+// attackStep is a run of `vegeta attack` with constant rate
+type attackStep struct {
+	stage    string
+	duration string
+	rate     rate
+}
+
+func stageName(index int) string {
+	return fmt.Sprintf("stage-%d", index)
+}
+
+// buildAttackSteps returns the steps of the attack, which is a single step unless stages are specified
+func buildAttackSteps(attack *vegetaV2.Attack) []attackStep {
+	if len(attack.Spec.Stages) > 0 {
+		return buildStageSteps(attack)
+	}
+	step := attackStep{
+		rate: parseRate(attack.Spec.Option.Rate),
+	}
+	if attack.Spec.Option.Duration != nil {
+		step.duration = formatDuration(attack.Spec.Option.Duration.Duration)
+	}
+	return []attackStep{step}
+}
+
+// buildStageSteps returns the steps to run the stages back to back.
+// Ramps run as vegetaV2.RampSteps steps, each of which has the interpolated rate at its midpoint.
+// Shorter ramps stored before the minimum duration was validated run as a step per second.
+func buildStageSteps(attack *vegetaV2.Attack) []attackStep {
+	var steps []attackStep
+	current := parseRate(attack.Spec.Option.Rate)
+	for i, stage := range attack.Spec.Stages {
+		name := stageName(i)
+		duration := stage.Duration.Duration
+		if stage.TargetRate == nil {
+			if stage.Rate != nil {
+				current = parseRate(stage.Rate)
+			}
+			steps = append(steps, attackStep{stage: name, duration: formatDuration(duration), rate: current})
+			continue
+		}
+
+		start, target := current.perSecond(), parseRate(stage.TargetRate)
+		current = target
+		n := vegetaV2.RampSteps
+		if seconds := int(duration / time.Second); seconds < n {
+			n = seconds
+		}
+		if n <= 1 {
+			steps = append(steps, attackStep{stage: name, duration: formatDuration(duration), rate: target})
+			continue
+		}
+
+		for k := 0; k < n; k++ {
+			// Boundaries are rounded in the same way, so that the steps sum up to the duration exactly
+			stepDuration := duration*time.Duration(k+1)/time.Duration(n) - duration*time.Duration(k)/time.Duration(n)
+			perSecond := start + (target.perSecond()-start)*(float64(k)+0.5)/float64(n)
+			steps = append(steps, attackStep{stage: name, duration: formatDuration(stepDuration), rate: ratePerMinute(perSecond)})
+		}
+	}
+	return steps
+}
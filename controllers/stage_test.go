@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestBuildStageSteps(t *testing.T) {
+	rateOf := func(value intstr.IntOrString) *intstr.IntOrString {
+		return &value
+	}
+	durationOf := func(d time.Duration) metaV1.Duration {
+		return metaV1.Duration{Duration: d}
+	}
+
+	// step is an attackStep with the rate formatted for comparison
+	type step struct {
+		stage    string
+		duration string
+		rate     string
+	}
+
+	tests := []struct {
+		name   string
+		rate   *intstr.IntOrString
+		stages []vegetaV2.Stage
+		want   []step
+	}{
+		{
+			name: "constant stages keeping the rate",
+			rate: rateOf(intstr.FromInt(5)),
+			stages: []vegetaV2.Stage{
+				{Duration: durationOf(time.Minute)},
+				{Duration: durationOf(30 * time.Second), Rate: rateOf(intstr.FromString("10/1m"))},
+				{Duration: durationOf(0)},
+			},
+			want: []step{
+				{stage: "stage-0", duration: "1m", rate: "5"},
+				{stage: "stage-1", duration: "30s", rate: "10/1m"},
+				{stage: "stage-2", duration: "0s", rate: "10/1m"},
+			},
+		},
+		{
+			name: "ramp by the maximum steps",
+			stages: []vegetaV2.Stage{
+				{Duration: durationOf(10 * time.Minute), TargetRate: rateOf(intstr.FromInt(100))},
+				{Duration: durationOf(time.Minute)},
+			},
+			want: []step{
+				{stage: "stage-0", duration: "1m", rate: "5"},
+				{stage: "stage-0", duration: "1m", rate: "15"},
+				{stage: "stage-0", duration: "1m", rate: "25"},
+				{stage: "stage-0", duration: "1m", rate: "35"},
+				{stage: "stage-0", duration: "1m", rate: "45"},
+				{stage: "stage-0", duration: "1m", rate: "55"},
+				{stage: "stage-0", duration: "1m", rate: "65"},
+				{stage: "stage-0", duration: "1m", rate: "75"},
+				{stage: "stage-0", duration: "1m", rate: "85"},
+				{stage: "stage-0", duration: "1m", rate: "95"},
+				{stage: "stage-1", duration: "1m", rate: "100"},
+			},
+		},
+		{
+			name: "ramp down by a step per second",
+			rate: rateOf(intstr.FromInt(40)),
+			stages: []vegetaV2.Stage{
+				{Duration: durationOf(3 * time.Second), TargetRate: rateOf(intstr.FromInt(10))},
+			},
+			want: []step{
+				{stage: "stage-0", duration: "1s", rate: "35"},
+				{stage: "stage-0", duration: "1s", rate: "25"},
+				{stage: "stage-0", duration: "1s", rate: "15"},
+			},
+		},
+		{
+			name: "ramp with the precision of requests per minute",
+			stages: []vegetaV2.Stage{
+				{Duration: durationOf(25 * time.Second), TargetRate: rateOf(intstr.FromInt(1))},
+			},
+			want: []step{
+				{stage: "stage-0", duration: "2.5s", rate: "3/1m"},
+				{stage: "stage-0", duration: "2.5s", rate: "9/1m"},
+				{stage: "stage-0", duration: "2.5s", rate: "15/1m"},
+				{stage: "stage-0", duration: "2.5s", rate: "21/1m"},
+				{stage: "stage-0", duration: "2.5s", rate: "27/1m"},
+				{stage: "stage-0", duration: "2.5s", rate: "33/1m"},
+				{stage: "stage-0", duration: "2.5s", rate: "39/1m"},
+				{stage: "stage-0", duration: "2.5s", rate: "45/1m"},
+				{stage: "stage-0", duration: "2.5s", rate: "51/1m"},
+				{stage: "stage-0", duration: "2.5s", rate: "57/1m"},
+			},
+		},
+		{
+			name: "ramp too short to divide",
+			rate: rateOf(intstr.FromInt(10)),
+			stages: []vegetaV2.Stage{
+				{Duration: durationOf(time.Second), TargetRate: rateOf(intstr.FromInt(20))},
+			},
+			want: []step{
+				{stage: "stage-0", duration: "1s", rate: "20"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attack := &vegetaV2.Attack{
+				Spec: vegetaV2.AttackSpec{
+					Option: vegetaV2.VegetaOption{Rate: tt.rate},
+					Stages: tt.stages,
+				},
+			}
+			var got []step
+			for _, s := range buildStageSteps(attack) {
+				got = append(got, step{stage: s.stage, duration: s.duration, rate: s.rate.String()})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildStageSteps() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
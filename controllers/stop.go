@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	"github.com/go-logr/logr"
+	batchV1 "k8s.io/api/batch/v1"
+	coreV1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// stopAnnotation is set to the job and its pods with the reason to stop the attack pods.
+	// The pods read their annotations through the downward API volume, and stop the attack by themselves,
+	// so that the pods and their logs are kept unlike scaling the job down.
+	stopAnnotation   = "vegeta.kaidotdev.github.io/stop"
+	podInfoMountPath = "/etc/vegeta-pod"
+
+	stopReasonSuspended = "Suspended"
+	stopReasonCancelled = "Cancelled"
+	stopReasonDeleted   = "Deleted"
+	stopReasonAborted   = "Aborted"
+)
+
+func getStopReason(job *batchV1.Job) string {
+	return job.Annotations[stopAnnotation]
+}
+
+func isJobStopped(job *batchV1.Job) bool {
+	_, ok := job.Annotations[stopAnnotation]
+	return ok
+}
+
+// isCancelRequested returns true when the cancel annotation has been changed since the job was created
+func isCancelRequested(attack *vegetaV2.Attack, job *batchV1.Job) bool {
+	value := attack.Annotations[vegetaV2.CancelAnnotation]
+	return value != "" && value != job.Annotations[vegetaV2.CancelAnnotation]
+}
+
+// stopJob records the reason in the job, so that the job is never scaled up or restarted by other than resume
+func (r *AttackReconciler) stopJob(ctx context.Context, logger logr.Logger, attack *vegetaV2.Attack, job *batchV1.Job, reason string) error {
+	patch := client.MergeFrom(job.DeepCopy())
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[stopAnnotation] = reason
+	if err := r.Patch(ctx, job, patch); err != nil {
+		return err
+	}
+	if reason == stopReasonDeadlineExceeded {
+		r.Recorder.Eventf(attack, coreV1.EventTypeNormal, "SuccessfulStopped", "Stopping job %q since the deadline has passed", job.Name)
+	} else {
+		r.Recorder.Eventf(attack, coreV1.EventTypeNormal, "SuccessfulStopped", "Stopping job %q since the attack is %s", job.Name, strings.ToLower(reason))
+	}
+	logger.V(1).Info("stop", "job", job.Name, "reason", reason)
+	return nil
+}
+
+// stopPods requests the attack pods of the stopped job which have not finished yet to stop
+func (r *AttackReconciler) stopPods(ctx context.Context, logger logr.Logger, job *batchV1.Job) error {
+	var pods coreV1.PodList
+	if err := r.List(
+		ctx,
+		&pods,
+		client.InNamespace(job.Namespace),
+		client.MatchingLabels{"controller-uid": string(job.UID)},
+	); err != nil {
+		return err
+	}
+
+	reason := getStopReason(job)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == coreV1.PodSucceeded || pod.Status.Phase == coreV1.PodFailed || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if _, ok := pod.Annotations[stopAnnotation]; ok {
+			continue
+		}
+		patch := client.MergeFrom(pod.DeepCopy())
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[stopAnnotation] = reason
+		if err := r.Patch(ctx, pod, patch); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		logger.V(1).Info("stop", "pod", pod.Name, "reason", reason)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// evaluateThresholds returns the violations of thresholds by the metrics, one for each threshold
+func evaluateThresholds(thresholds *vegetaV2.Thresholds, metrics *vegetaMetrics) []string {
+	var violations []string
+
+	if thresholds.MaxLatencyP99 != nil && metrics.Latencies.P99 > thresholds.MaxLatencyP99.Duration {
+		violations = append(violations, fmt.Sprintf("maxLatencyP99: %s > %s", metrics.Latencies.P99, thresholds.MaxLatencyP99.Duration))
+	}
+	if thresholds.MaxLatencyP95 != nil && metrics.Latencies.P95 > thresholds.MaxLatencyP95.Duration {
+		violations = append(violations, fmt.Sprintf("maxLatencyP95: %s > %s", metrics.Latencies.P95, thresholds.MaxLatencyP95.Duration))
+	}
+	if thresholds.MaxLatencyMean != nil && metrics.Latencies.Mean > thresholds.MaxLatencyMean.Duration {
+		violations = append(violations, fmt.Sprintf("maxLatencyMean: %s > %s", metrics.Latencies.Mean, thresholds.MaxLatencyMean.Duration))
+	}
+	if thresholds.MinSuccessRatio != "" {
+		// The format has been validated by CRD
+		minSuccessRatio, _ := strconv.ParseFloat(thresholds.MinSuccessRatio, 64)
+		if metrics.Success < minSuccessRatio {
+			violations = append(violations, fmt.Sprintf("minSuccessRatio: %.4f < %s", metrics.Success, thresholds.MinSuccessRatio))
+		}
+	}
+	if thresholds.MinThroughput != "" {
+		minThroughput, _ := strconv.ParseFloat(thresholds.MinThroughput, 64)
+		if metrics.Throughput < minThroughput {
+			violations = append(violations, fmt.Sprintf("minThroughput: %.2f < %s", metrics.Throughput, thresholds.MinThroughput))
+		}
+	}
+	if len(thresholds.AllowedStatusCodes) > 0 {
+		allowed := make(map[string]struct{}, len(thresholds.AllowedStatusCodes))
+		for _, code := range thresholds.AllowedStatusCodes {
+			allowed[strconv.Itoa(int(code))] = struct{}{}
+		}
+		var disallowed []string
+		for code := range metrics.StatusCodes {
+			if _, ok := allowed[code]; !ok {
+				disallowed = append(disallowed, code)
+			}
+		}
+		if len(disallowed) > 0 {
+			sort.Strings(disallowed)
+			violations = append(violations, fmt.Sprintf("allowedStatusCodes: %s not allowed", strings.Join(disallowed, ",")))
+		}
+	}
+	if thresholds.MaxErrors != nil {
+		errors := int64(metrics.Requests) - int64(math.Round(metrics.Success*float64(metrics.Requests)))
+		if errors > *thresholds.MaxErrors {
+			violations = append(violations, fmt.Sprintf("maxErrors: %d > %d", errors, *thresholds.MaxErrors))
+		}
+	}
+
+	return violations
+}
+
+// buildPassedCondition evaluates thresholds once the attack has finished
+func buildPassedCondition(attack *vegetaV2.Attack, phase vegetaV2.AttackPhase, metrics *vegetaMetrics) vegetaV2.Condition {
+	condition := vegetaV2.Condition{
+		Type:               vegetaV2.AttackPassed,
+		ObservedGeneration: attack.Generation,
+	}
+
+	switch {
+	case phase == vegetaV2.AttackAborted || phase == vegetaV2.AttackSuspended || phase == vegetaV2.AttackCancelled:
+		condition.Status = metaV1.ConditionFalse
+		condition.Reason = "Attack" + string(phase)
+		condition.Message = fmt.Sprintf("Attack was %s before it finished", strings.ToLower(string(phase)))
+	case phase == vegetaV2.AttackFailed:
+		// The results of the failed attack are partial, so that they never pass however good they are
+		condition.Status = metaV1.ConditionFalse
+		condition.Reason = "AttackFailed"
+		condition.Message = "Attack failed before it finished"
+	case phase != vegetaV2.AttackSucceeded:
+		condition.Status = metaV1.ConditionUnknown
+		condition.Reason = "AttackNotFinished"
+	case metrics == nil:
+		condition.Status = metaV1.ConditionFalse
+		condition.Reason = "ReportUnavailable"
+		condition.Message = "No complete results were collected from the attack pods"
+	default:
+		if violations := evaluateThresholds(attack.Spec.Thresholds, metrics); len(violations) > 0 {
+			condition.Status = metaV1.ConditionFalse
+			condition.Reason = "ThresholdsViolated"
+			condition.Message = strings.Join(violations, "; ")
+		} else {
+			condition.Status = metaV1.ConditionTrue
+			condition.Reason = "ThresholdsSatisfied"
+		}
+	}
+
+	return condition
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestMetrics() *vegetaMetrics {
+	metrics := &vegetaMetrics{
+		Requests:    100,
+		Success:     0.97,
+		Throughput:  48.5,
+		StatusCodes: map[string]int{"200": 97, "503": 3},
+	}
+	metrics.Latencies.Mean = 20 * time.Millisecond
+	metrics.Latencies.P95 = 80 * time.Millisecond
+	metrics.Latencies.P99 = 150 * time.Millisecond
+	return metrics
+}
+
+func TestEvaluateThresholds(t *testing.T) {
+	maxErrors := func(n int64) *int64 {
+		return &n
+	}
+
+	tests := []struct {
+		name       string
+		thresholds vegetaV2.Thresholds
+		want       []string
+	}{
+		{
+			name:       "no thresholds",
+			thresholds: vegetaV2.Thresholds{},
+		},
+		{
+			name: "satisfied",
+			thresholds: vegetaV2.Thresholds{
+				MaxLatencyP99:      &metaV1.Duration{Duration: 200 * time.Millisecond},
+				MaxLatencyP95:      &metaV1.Duration{Duration: 80 * time.Millisecond},
+				MaxLatencyMean:     &metaV1.Duration{Duration: 50 * time.Millisecond},
+				MinSuccessRatio:    "0.95",
+				MinThroughput:      "40",
+				AllowedStatusCodes: []int32{200, 503},
+				MaxErrors:          maxErrors(3),
+			},
+		},
+		{
+			name: "violated",
+			thresholds: vegetaV2.Thresholds{
+				MaxLatencyP99:      &metaV1.Duration{Duration: 100 * time.Millisecond},
+				MaxLatencyP95:      &metaV1.Duration{Duration: 50 * time.Millisecond},
+				MaxLatencyMean:     &metaV1.Duration{Duration: 10 * time.Millisecond},
+				MinSuccessRatio:    "0.99",
+				MinThroughput:      "50",
+				AllowedStatusCodes: []int32{200},
+				MaxErrors:          maxErrors(2),
+			},
+			want: []string{
+				"maxLatencyP99: 150ms > 100ms",
+				"maxLatencyP95: 80ms > 50ms",
+				"maxLatencyMean: 20ms > 10ms",
+				"minSuccessRatio: 0.9700 < 0.99",
+				"minThroughput: 48.50 < 50",
+				"allowedStatusCodes: 503 not allowed",
+				"maxErrors: 3 > 2",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evaluateThresholds(&tt.thresholds, newTestMetrics()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("evaluateThresholds() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildPassedCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		phase      vegetaV2.AttackPhase
+		metrics    *vegetaMetrics
+		thresholds vegetaV2.Thresholds
+		wantStatus metaV1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "running",
+			phase:      vegetaV2.AttackRunning,
+			metrics:    newTestMetrics(),
+			wantStatus: metaV1.ConditionUnknown,
+			wantReason: "AttackNotFinished",
+		},
+		{
+			name:       "failed with good results",
+			phase:      vegetaV2.AttackFailed,
+			metrics:    newTestMetrics(),
+			wantStatus: metaV1.ConditionFalse,
+			wantReason: "AttackFailed",
+		},
+		{
+			name:       "aborted",
+			phase:      vegetaV2.AttackAborted,
+			metrics:    newTestMetrics(),
+			wantStatus: metaV1.ConditionFalse,
+			wantReason: "AttackAborted",
+		},
+		{
+			name:       "succeeded without results",
+			phase:      vegetaV2.AttackSucceeded,
+			wantStatus: metaV1.ConditionFalse,
+			wantReason: "ReportUnavailable",
+		},
+		{
+			name:       "succeeded violating thresholds",
+			phase:      vegetaV2.AttackSucceeded,
+			metrics:    newTestMetrics(),
+			thresholds: vegetaV2.Thresholds{MinSuccessRatio: "0.99"},
+			wantStatus: metaV1.ConditionFalse,
+			wantReason: "ThresholdsViolated",
+		},
+		{
+			name:       "succeeded satisfying thresholds",
+			phase:      vegetaV2.AttackSucceeded,
+			metrics:    newTestMetrics(),
+			thresholds: vegetaV2.Thresholds{MinSuccessRatio: "0.95"},
+			wantStatus: metaV1.ConditionTrue,
+			wantReason: "ThresholdsSatisfied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attack := &vegetaV2.Attack{
+				ObjectMeta: metaV1.ObjectMeta{Generation: 2},
+				Spec:       vegetaV2.AttackSpec{Thresholds: &tt.thresholds},
+			}
+			got := buildPassedCondition(attack, tt.phase, tt.metrics)
+			if got.Type != vegetaV2.AttackPassed || got.Status != tt.wantStatus || got.Reason != tt.wantReason || got.ObservedGeneration != 2 {
+				t.Errorf("buildPassedCondition() = %+v, want %s with %s", got, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	tlsHashAnnotation = "vegeta.kaidotdev.github.io/tls-hash"
+	tlsMountPath      = "/var/lib/vegeta-tls"
+	// Paths in the tls volume, which are not the keys of referenced objects so that they never collide
+	tlsClientCertPath = "client.crt"
+	tlsClientKeyPath  = "client.key"
+	tlsRootCAsPath    = "root-cas.crt"
+)
+
+// reconcileTLS checks that the objects referenced by tls are available to the attack pods.
+// It returns the hash of the certificates, which changes the pod template when any of them changes.
+func (r *AttackReconciler) reconcileTLS(ctx context.Context, attack *vegetaV2.Attack) (string, vegetaV2.Condition, error) {
+	condition := vegetaV2.Condition{
+		Type:               vegetaV2.AttackTLSAvailable,
+		Status:             metaV1.ConditionFalse,
+		ObservedGeneration: attack.Generation,
+	}
+
+	tls := attack.Spec.TLS
+	if tls == nil || (tls.ClientCertSecretRef == nil && tls.RootCAs == nil) {
+		condition.Status = metaV1.ConditionTrue
+		condition.Reason = "NoCertificates"
+		return "", condition, nil
+	}
+
+	var values []interface{}
+	if ref := tls.ClientCertSecretRef; ref != nil {
+		for _, key := range []string{v1.TLSCertKey, v1.TLSPrivateKeyKey} {
+			secretRef := &v1.SecretKeySelector{LocalObjectReference: *ref, Key: key}
+			value, err := r.resolveKeyRef(ctx, attack, nil, secretRef, " of the client certificate", &condition)
+			if value == nil || err != nil {
+				return "", condition, err
+			}
+			values = append(values, value)
+		}
+	}
+	if source := tls.RootCAs; source != nil {
+		switch {
+		case (source.ConfigMapKeyRef == nil) != (source.SecretKeyRef == nil):
+			value, err := r.resolveKeyRef(ctx, attack, source.ConfigMapKeyRef, source.SecretKeyRef, " of the root CAs", &condition)
+			if value == nil || err != nil {
+				return "", condition, err
+			}
+			values = append(values, value)
+		default:
+			condition.Reason = "InvalidCertificateSource"
+			condition.Message = "Exactly one of configMapKeyRef and secretKeyRef must be specified in rootCAs"
+			return "", condition, nil
+		}
+	}
+
+	condition.Status = metaV1.ConditionTrue
+	condition.Reason = "CertificatesAvailable"
+	hash, err := computeHash(values)
+	return hash, condition, err
+}
+
+// buildTLSVolumeSource returns the volume which has the client certificate and the root CAs,
+// or nil when neither is specified.
+// They may come from different objects, so that they are projected into a single volume.
+func buildTLSVolumeSource(attack *vegetaV2.Attack) *v1.VolumeSource {
+	tls := attack.Spec.TLS
+	if tls == nil {
+		return nil
+	}
+
+	var sources []v1.VolumeProjection
+	if tls.ClientCertSecretRef != nil {
+		sources = append(sources, v1.VolumeProjection{
+			Secret: &v1.SecretProjection{
+				LocalObjectReference: *tls.ClientCertSecretRef,
+				Items: []v1.KeyToPath{
+					{
+						Key:  v1.TLSCertKey,
+						Path: tlsClientCertPath,
+					},
+					{
+						Key:  v1.TLSPrivateKeyKey,
+						Path: tlsClientKeyPath,
+					},
+				},
+			},
+		})
+	}
+	if source := tls.RootCAs; source != nil {
+		switch {
+		case source.ConfigMapKeyRef != nil:
+			sources = append(sources, v1.VolumeProjection{
+				ConfigMap: &v1.ConfigMapProjection{
+					LocalObjectReference: source.ConfigMapKeyRef.LocalObjectReference,
+					Items: []v1.KeyToPath{
+						{
+							Key:  source.ConfigMapKeyRef.Key,
+							Path: tlsRootCAsPath,
+						},
+					},
+				},
+			})
+		case source.SecretKeyRef != nil:
+			sources = append(sources, v1.VolumeProjection{
+				Secret: &v1.SecretProjection{
+					LocalObjectReference: source.SecretKeyRef.LocalObjectReference,
+					Items: []v1.KeyToPath{
+						{
+							Key:  source.SecretKeyRef.Key,
+							Path: tlsRootCAsPath,
+						},
+					},
+				},
+			})
+		}
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+
+	return &v1.VolumeSource{
+		Projected: &v1.ProjectedVolumeSource{
+			Sources: sources,
+		},
+	}
+}
+
+// buildTLSFlags returns the flags of vegeta to use the tls volume and the other TLS configuration
+func buildTLSFlags(attack *vegetaV2.Attack) []attackFlag {
+	tls := attack.Spec.TLS
+	if tls == nil {
+		return nil
+	}
+
+	var flags []attackFlag
+	if tls.ClientCertSecretRef != nil {
+		flags = append(
+			flags,
+			attackFlag{name: "cert", value: tlsMountPath + "/" + tlsClientCertPath},
+			attackFlag{name: "key", value: tlsMountPath + "/" + tlsClientKeyPath},
+		)
+	}
+	if tls.RootCAs != nil {
+		flags = append(flags, attackFlag{name: "root-certs", value: tlsMountPath + "/" + tlsRootCAsPath})
+	}
+	return flags
+}
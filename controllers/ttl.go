@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	vegetaV2 "vegeta-controller/api/v2"
+
+	"github.com/go-logr/logr"
+	batchV1 "k8s.io/api/batch/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// hasAttackFinished returns true when the attack of the current spec has stopped for good.
+// The suspended attack has not finished, since it may be resumed.
+func hasAttackFinished(attack *vegetaV2.Attack) bool {
+	if attack.Status.ObservedGeneration != attack.Generation {
+		return false
+	}
+	switch attack.Status.Phase {
+	case vegetaV2.AttackSucceeded, vegetaV2.AttackFailed, vegetaV2.AttackAborted, vegetaV2.AttackCancelled:
+		return true
+	}
+	return false
+}
+
+// isAttackCleanedUp returns true when the job of the current spec has been deleted by the TTL
+func isAttackCleanedUp(attack *vegetaV2.Attack) bool {
+	condition := vegetaV2.FindCondition(attack.Status.Conditions, vegetaV2.AttackCleanedUp)
+	return condition != nil && condition.Status == metaV1.ConditionTrue && condition.ObservedGeneration == attack.Generation
+}
+
+// ttlAfterFinished returns the TTL of the attack, or nil when it is never cleaned up
+func (r *AttackReconciler) ttlAfterFinished(attack *vegetaV2.Attack) *time.Duration {
+	seconds := attack.Spec.TTLSecondsAfterFinished
+	if seconds == nil {
+		seconds = r.DefaultTTLSecondsAfterFinished
+	}
+	if seconds == nil {
+		return nil
+	}
+	ttl := time.Duration(*seconds) * time.Second
+	return &ttl
+}
+
+// reconcileTTL cleans up the finished attack when its TTL has passed, or requeues it until then.
+// The status is updated before, so that the results have been exported into the status and the report by then.
+func (r *AttackReconciler) reconcileTTL(ctx context.Context, logger logr.Logger, attack *vegetaV2.Attack) (ctrl.Result, error) {
+	ttl := r.ttlAfterFinished(attack)
+	if ttl == nil || !hasAttackFinished(attack) || attack.Status.CompletionTime == nil {
+		return ctrl.Result{}, nil
+	}
+	if remaining := time.Until(attack.Status.CompletionTime.Add(*ttl)); remaining > 0 {
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+
+	attackName := types.NamespacedName{Name: attack.Name, Namespace: attack.Namespace}
+	r.results.forget(attackName)
+	r.live.forget(attackName)
+	r.aborts.forget(attackName)
+
+	if attack.Spec.CleanupPolicy == vegetaV2.AttackCleanupPolicy {
+		return ctrl.Result{}, r.cleanupAttack(ctx, logger, attack)
+	}
+	return ctrl.Result{}, r.cleanupJob(ctx, logger, attack)
+}
+
+// cleanupJob deletes the job, its pods and the config maps for them, and records it in the status,
+// so that the job is not created again until the spec changes
+func (r *AttackReconciler) cleanupJob(ctx context.Context, logger logr.Logger, attack *vegetaV2.Attack) error {
+	job := &batchV1.Job{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      attack.Name + "-attack",
+			Namespace: attack.Namespace,
+		},
+	}
+	if err := r.Delete(ctx, job, client.PropagationPolicy(metaV1.DeletePropagationForeground)); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	for _, name := range []string{attack.Name + "-scenario", attack.Name + "-nsswitch", attack.Name + "-bodies", attack.Name + "-start"} {
+		configMap := &coreV1.ConfigMap{
+			ObjectMeta: metaV1.ObjectMeta{
+				Name:      name,
+				Namespace: attack.Namespace,
+			},
+		}
+		if err := r.Delete(ctx, configMap); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	r.Recorder.Eventf(attack, coreV1.EventTypeNormal, "SuccessfulDeleted", "Deleted job and its config maps after ttlSecondsAfterFinished: %q", job.Name)
+	logger.V(1).Info("cleanup", "job", job.Name)
+
+	vegetaV2.SetCondition(&attack.Status.Conditions, vegetaV2.Condition{
+		Type:               vegetaV2.AttackCleanedUp,
+		Status:             metaV1.ConditionTrue,
+		ObservedGeneration: attack.Generation,
+		Reason:             "TTLExpired",
+		Message:            fmt.Sprintf("Job %q was deleted after the attack finished", job.Name),
+	})
+	return r.Status().Update(ctx, attack)
+}
+
+// cleanupAttack deletes Attack itself, which deletes the other owned resources through garbage collection.
+// The report config maps are released beforehand, since they are the only record of the results after that.
+func (r *AttackReconciler) cleanupAttack(ctx context.Context, logger logr.Logger, attack *vegetaV2.Attack) error {
+	if err := r.releaseReport(ctx, logger, attack); err != nil {
+		return err
+	}
+
+	if err := r.Delete(ctx, attack, client.PropagationPolicy(metaV1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	r.Recorder.Eventf(attack, coreV1.EventTypeNormal, "SuccessfulDeleted", "Deleted attack after ttlSecondsAfterFinished: %q", attack.Name)
+	logger.V(1).Info("cleanup", "attack", attack.Name)
+	return nil
+}
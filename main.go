@@ -3,11 +3,14 @@ package main
 import (
 	"flag"
 	"os"
+	"time"
 	"vegeta-controller/controllers"
 
 	vegetaV1 "vegeta-controller/api/v1"
+	vegetaV2 "vegeta-controller/api/v2"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -24,6 +27,7 @@ func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
 
 	_ = vegetaV1.AddToScheme(scheme)
+	_ = vegetaV2.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -31,10 +35,20 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var vegetaImage string
+	var enableWebhook bool
+	var defaultTTLSecondsAfterFinished int
+	var finalizeTimeout time.Duration
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager.")
 	flag.StringVar(&vegetaImage, "vegeta-image", "peterevans/vegeta:6.7", "Vegeta image path used by vegeta-controller")
+	flag.BoolVar(&enableWebhook, "enable-webhook", true,
+		"Enable conversion and admission webhooks, which need serving certificates in /tmp/k8s-webhook-server/serving-certs. "+
+			"They must not be disabled while CRDs convert their versions by the webhook.")
+	flag.IntVar(&defaultTTLSecondsAfterFinished, "default-ttl-seconds-after-finished", -1,
+		"Seconds after finished attacks without ttlSecondsAfterFinished are cleaned up. They are never cleaned up when negative.")
+	flag.DurationVar(&finalizeTimeout, "finalize-timeout", 5*time.Minute,
+		"How long deleted attacks wait for their pods to stop to export the results before they are released.")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.Logger(true))
@@ -51,16 +65,51 @@ func main() {
 		os.Exit(1)
 	}
 
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create clientset")
+		os.Exit(1)
+	}
+
+	var defaultTTL *int32
+	if defaultTTLSecondsAfterFinished >= 0 {
+		ttl := int32(defaultTTLSecondsAfterFinished)
+		defaultTTL = &ttl
+	}
+
 	if err := (&controllers.AttackReconciler{
-		Client:      mgr.GetClient(),
-		Log:         ctrl.Log.WithName("controllers").WithName("Attack"),
-		Scheme:      mgr.GetScheme(),
-		Recorder:    mgr.GetEventRecorderFor("vegeta-controller"),
-		VegetaImage: vegetaImage,
+		Client:                         mgr.GetClient(),
+		Log:                            ctrl.Log.WithName("controllers").WithName("Attack"),
+		Scheme:                         mgr.GetScheme(),
+		Recorder:                       mgr.GetEventRecorderFor("vegeta-controller"),
+		Clientset:                      clientset,
+		VegetaImage:                    vegetaImage,
+		DefaultTTLSecondsAfterFinished: defaultTTL,
+		FinalizeTimeout:                finalizeTimeout,
+		APIReader:                      mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Attack")
 		os.Exit(1)
 	}
+	if err := (&controllers.CronAttackReconciler{
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("CronAttack"),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("vegeta-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CronAttack")
+		os.Exit(1)
+	}
+	if enableWebhook {
+		if err := (&vegetaV2.Attack{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Attack")
+			os.Exit(1)
+		}
+		if err := (&vegetaV2.CronAttack{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "CronAttack")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")